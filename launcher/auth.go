@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/auth"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+	"golang.org/x/term"
+	"google.golang.org/api/youtube/v3"
+)
+
+func printAuthUsage() {
+	fmt.Println("YouTube authorization commands")
+	fmt.Println()
+	fmt.Println("Usage: launcher auth <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  login    Sign in to YouTube (also used to switch accounts)")
+	fmt.Println("  status   Show the signed-in channel, scopes and token expiry")
+	fmt.Println("  logout   Revoke and delete the saved token")
+	fmt.Println("  migrate  Move the token to another token store")
+	fmt.Println("  export   Write a passphrase-sealed bundle of the client and token for another machine")
+	fmt.Println("  import   Sign in from a bundle made by 'auth export', without a browser")
+	fmt.Println()
+	fmt.Println("Run 'launcher auth <command> --help' for more information.")
+}
+
+// cmdAuth handles the auth subcommand
+func cmdAuth(args []string) {
+	if len(args) < 1 {
+		printAuthUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "login":
+		cmdAuthLogin(args[1:])
+	case "status":
+		cmdAuthStatus(args[1:])
+	case "logout":
+		cmdAuthLogout(args[1:])
+	case "migrate":
+		cmdAuthMigrate(args[1:])
+	case "export":
+		cmdAuthExport(args[1:])
+	case "import":
+		cmdAuthImport(args[1:])
+	case "-help", "--help", "help":
+		printAuthUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown auth command: %s\n\n", args[0])
+		printAuthUsage()
+		os.Exit(1)
+	}
+}
+
+func cmdAuthLogin(args []string) {
+	fs := flag.NewFlagSet("auth login", flag.ExitOnError)
+	noBrowser := fs.Bool("no-browser", false, "Authorize by pasting the redirected URL instead of opening a browser (for headless machines)")
+	fs.Usage = func() { printFlagUsage(fs, "launcher auth login") }
+	fs.Parse(args)
+
+	baseDir := activeProfile.Dir
+	config, err := loadOAuthConfig(baseDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	tok, err := getTokenFromWeb(config, *noBrowser)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error signing in: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Replaces any previous token, which is how accounts are switched
+	if err := os.MkdirAll(baseDir, 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating profile directory: %v\n", err)
+		os.Exit(1)
+	}
+	store, err := activeProfile.tokenStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saving token to: %s\n", store)
+	if err := store.Save(tok); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving token: %v\n", err)
+		os.Exit(1)
+	}
+
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
+		os.Exit(1)
+	}
+	channel, err := scheduler.Channel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not look up channel: %v\n", err)
+		return
+	}
+	fmt.Printf("Signed in as: %s\n", channel.Snippet.Title)
+}
+
+func cmdAuthStatus(args []string) {
+	fs := flag.NewFlagSet("auth status", flag.ExitOnError)
+	fs.Usage = func() { printFlagUsage(fs, "launcher auth status") }
+	fs.Parse(args)
+
+	baseDir := activeProfile.Dir
+	config, err := loadOAuthConfig(baseDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := activeProfile.tokenStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	src, err := getTokenSource(config, activeProfile)
+	if errors.Is(err, errNotLoggedIn) {
+		fmt.Println("Not signed in. Run 'launcher auth login' to sign in.")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading token: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Refreshes the access token if needed, which also proves the refresh token still works
+	tok, err := src.Token()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Token store:     %s\n", store)
+
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
+		os.Exit(1)
+	}
+	if channel, err := scheduler.Channel(); err != nil {
+		fmt.Printf("Channel:         unknown (%v)\n", err)
+	} else {
+		fmt.Printf("Channel:         %s (%s)\n", channel.Snippet.Title, channel.Id)
+	}
+
+	if info, err := auth.FetchTokenInfo(context.Background(), tok.AccessToken); err != nil {
+		fmt.Printf("Scopes:          unknown (%v)\n", err)
+	} else {
+		fmt.Printf("Scopes:          %s\n", strings.Join(info.Scopes, " "))
+	}
+
+	if tok.Expiry.IsZero() {
+		fmt.Println("Access token:    does not expire")
+	} else {
+		fmt.Printf("Access token:    expires %s (in %s)\n", tok.Expiry.Local().Format("2006-01-02 15:04:05"), time.Until(tok.Expiry).Round(time.Second))
+	}
+	if tok.RefreshToken != "" {
+		fmt.Println("Refresh token:   present")
+	} else {
+		fmt.Println("Refresh token:   missing (run 'launcher auth login' again)")
+	}
+}
+
+func cmdAuthLogout(args []string) {
+	fs := flag.NewFlagSet("auth logout", flag.ExitOnError)
+	fs.Usage = func() { printFlagUsage(fs, "launcher auth logout") }
+	fs.Parse(args)
+
+	store, err := activeProfile.tokenStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	tok, err := store.Load()
+	if errors.Is(err, auth.ErrNoToken) {
+		fmt.Println("Not signed in.")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading token: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Revoking the refresh token also invalidates its access tokens
+	revokeToken := tok.RefreshToken
+	if revokeToken == "" {
+		revokeToken = tok.AccessToken
+	}
+	if err := auth.Revoke(context.Background(), revokeToken); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not revoke token: %v\n", err)
+	} else {
+		fmt.Println("Token revoked")
+	}
+
+	if err := store.Delete(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error deleting token: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted token from %s\n", store)
+}
+
+// bundlePassphraseEnv supplies the bundle passphrase without a prompt, e.g. over SSH without a terminal
+const bundlePassphraseEnv = "LAUNCHER_BUNDLE_PASSPHRASE"
+
+func readBundlePassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(bundlePassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("set %s to the bundle passphrase", bundlePassphraseEnv)
+	}
+	return promptPassphrase("Bundle passphrase", confirm)
+}
+
+func cmdAuthExport(args []string) {
+	fs := flag.NewFlagSet("auth export", flag.ExitOnError)
+	out := fs.String("out", "launcher-auth.bundle", "File to write the bundle to")
+	fs.Usage = func() { printFlagUsage(fs, "launcher auth export") }
+	fs.Parse(args)
+
+	credentials, credPath, err := readCredentials(activeProfile.Dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := activeProfile.tokenStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	tok, err := store.Load()
+	if errors.Is(err, auth.ErrNoToken) {
+		fmt.Fprintln(os.Stderr, "Error: not signed in; run 'launcher auth login' first")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading token: %v\n", err)
+		os.Exit(1)
+	}
+
+	passphrase, err := readBundlePassphrase(true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if passphrase == "" {
+		fmt.Fprintln(os.Stderr, "Error: the bundle passphrase can't be empty")
+		os.Exit(1)
+	}
+
+	data, err := auth.SealBundle(credentials, tok, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, data, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing bundle: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Wrote %s (client from %s, token from %s)\n", *out, credPath, store)
+	fmt.Println("On the station, run: launcher auth import " + filepath.Base(*out))
+	fmt.Println("The bundle holds a working refresh token. Delete it once imported, and don't run")
+	fmt.Println("'auth logout' here afterwards: revoking the token would sign the station out too.")
+}
+
+func cmdAuthImport(args []string) {
+	fs := flag.NewFlagSet("auth import", flag.ExitOnError)
+	fs.Usage = func() { printFlagUsage(fs, "launcher auth import <bundle>") }
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading bundle: %v\n", err)
+		os.Exit(1)
+	}
+	passphrase, err := readBundlePassphrase(false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	bundle, err := auth.OpenBundle(data, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening bundle: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := google.ConfigFromJSON(bundle.Credentials, youtube.YoutubeScope); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: bundle has invalid client credentials: %v\n", err)
+		os.Exit(1)
+	}
+
+	baseDir := activeProfile.Dir
+	if err := os.MkdirAll(baseDir, 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating profile directory: %v\n", err)
+		os.Exit(1)
+	}
+	store, err := activeProfile.tokenStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Keep what was there so a bundle that doesn't work can be rolled back
+	credPath := filepath.Join(baseDir, credentialsFile)
+	prevCredentials, credErr := os.ReadFile(credPath)
+	prevToken, tokErr := store.Load()
+	rollback := func() {
+		if credErr == nil {
+			os.WriteFile(credPath, prevCredentials, 0600)
+		} else {
+			os.Remove(credPath)
+		}
+		if tokErr == nil {
+			store.Save(prevToken)
+		} else {
+			store.Delete()
+		}
+	}
+
+	if err := os.WriteFile(credPath, bundle.Credentials, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", credPath, err)
+		os.Exit(1)
+	}
+	if err := store.Save(bundle.Token); err != nil {
+		rollback()
+		fmt.Fprintf(os.Stderr, "Error saving token: %v\n", err)
+		os.Exit(1)
+	}
+
+	// A test call proves the client and refresh token work together before anything relies on them
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		rollback()
+		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
+		os.Exit(1)
+	}
+	channel, err := scheduler.Channel()
+	if err != nil {
+		rollback()
+		fmt.Fprintf(os.Stderr, "Error: the bundle didn't work, nothing was changed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Imported credentials to %s and token to %s\n", credPath, store)
+	fmt.Printf("Signed in as: %s\n", channel.Snippet.Title)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"launcher/internal/obsws"
+	"launcher/internal/wx"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	configFile = "config.yaml"
+	envPrefix  = "LAUNCHER_"
+)
+
+// Where a setting's value came from, highest precedence first. Flags beat all of them.
+const (
+	sourceEnv     = "env"
+	sourceConfig  = "config"
+	sourceDefault = "default"
+)
+
+type setting struct {
+	Key     string
+	Default string
+	Usage   string
+}
+
+// settings are the keys config.yaml and LAUNCHER_* variables can set. A key is a flag name
+// with '-' replaced by '_', and sets that flag's default for every command that has it.
+var settings = []setting{
+	{"city", "", "City for sunrise/sunset lookup (empty: IP geolocation)"},
+	{"title", "", "Broadcast title template (empty: title_template)"},
+	{"description", "", "Broadcast description template"},
+	{"privacy", "public", "Privacy status: public, unlisted, or private"},
+	{"time", "SUNRISE", "Start time: a sun event or 'YYYY-MM-DDTHH:MM:SS'"},
+	{"end_time", "SUNSET", "End time: a sun event or 'YYYY-MM-DDTHH:MM:SS'"},
+	{"start_offset", fmt.Sprint(defaultStartOffset), "Minutes offset from the start sun event"},
+	{"end_offset", fmt.Sprint(defaultEndOffset), "Minutes offset from the end sun event"},
+	{"sun_source", sunSourceNOAA, "Sun times source: noaa or api"},
+	{"obs_path", "", "OBS executable (empty: the platform's default install)"},
+	{"skip_obs", "false", "Don't start OBS"},
+	{"obs_address", obsws.DefaultAddress, "obs-websocket server address"},
+	{"obs_password", "", "obs-websocket password (also $" + obsPasswordEnv + ")"},
+	{"obs_timeout", (2 * time.Minute).String(), "How long to wait for OBS to start streaming"},
+	{"obs_assume_streaming", "false", "Go live anyway if obs-websocket can't be reached after launching OBS"},
+	{"live_timeout", DefaultGoLiveOptions().Timeout.String(), "How long to wait for YouTube to go live"},
+	{"poll_interval", DefaultGoLiveOptions().PollInterval.String(), "Initial delay between YouTube status checks"},
+	{"station_url", wx.DefaultBaseURL, "Weather station files URL, before the YYYYMMDD date"},
+	{"station_suffix", wx.DefaultSuffix, "Weather station files suffix, after the date"},
+	{"station_begin", wx.DefaultBegin, "Time of day (HH:MM) the station starts reporting"},
+	{"station_end", wx.DefaultEnd, "Time of day (HH:MM) the station stops reporting"},
+	{"station_interval", time.Minute.String(), "How often to read the weather station"},
+	{"wx_gate", gateOff, "Weather check before going live: off, skip or delay"},
+	{"wx_max_age", (15 * time.Minute).String(), "Station counts as down if its latest reading is older"},
+	{"wx_max_wind", "0", "Highest sustained wind in mph to go live (0: no limit)"},
+	{"wx_max_gust", "0", "Highest gust in mph to go live (0: no limit)"},
+	{"wx_window", (10 * time.Minute).String(), "How long the wind or gusts must stay above their limit to fail the weather check"},
+	{"wx_retry", (5 * time.Minute).String(), "How often a delayed stream rechecks the station"},
+	{"wx_max_delay", (2 * time.Hour).String(), "How long to delay before skipping the stream"},
+	{"wx_summary", "true", "Add the day's weather summary to the video description after the stream ends"},
+	{"chapters", "true", "Add chapters from the wind conditions to the video description after the stream ends"},
+	{"chapter_calm", "5", "Sustained wind in mph below which a chapter is 'Calm'"},
+	{"chapter_gust", "20", "Gust in mph at or above which a chapter is 'Gusty'"},
+	{"chapter_min_length", (15 * time.Minute).String(), "Shortest chapter"},
+	{"category_id", "", "YouTube video category ID (default: the channel's)"},
+	{"tags", "", "Comma-separated video tags"},
+	{"language", "", "Language of the title and description"},
+	{"audio_language", "", "Language spoken in the stream"},
+	{"playlist", "", "Playlist to add each video to, a Go template"},
+	{"thumbnail", "", "Image to upload as the thumbnail instead of rendering one, or 'none'"},
+	{"overlay_address", "127.0.0.1:8090", "Address 'overlay serve' listens on"},
+	{"overlay_css", "", "CSS file to restyle the overlay"},
+	{"stream_title", youtubeStreamTitle, "Reusable YouTube live stream (ingest key)"},
+	{"title_template", defaultTitleTemplate, "Default broadcast title template; {date} is MM/DD/YYYY"},
+	{"token_store", tokenStoreFile, "Where the OAuth token is kept: " + strings.Join(tokenStores, ", ")},
+}
+
+// secretSettings are masked in 'config show'
+var secretSettings = map[string]bool{"obs_password": true}
+
+func lookupSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func settingEnv(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// setting returns a key's effective value and its source, ignoring flags:
+// LAUNCHER_<KEY> beats the profile's config.yaml, which beats the built-in default
+func (p *Profile) setting(key string) (string, string) {
+	if value, ok := os.LookupEnv(settingEnv(key)); ok {
+		return value, sourceEnv
+	}
+	if value, ok := p.Config[key]; ok && value != nil {
+		// A YAML list, e.g. tags: [weather, wind], is the same as a comma-separated value
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			return strings.Join(items, ","), sourceConfig
+		}
+		return fmt.Sprint(value), sourceConfig
+	}
+	s, _ := lookupSetting(key)
+	return s.Default, sourceDefault
+}
+
+func (p *Profile) settingValue(key string) string {
+	value, _ := p.setting(key)
+	return value
+}
+
+// parseFlags parses a command's arguments after setting the defaults of its flags from the
+// active profile's settings, so explicit flags take precedence over env, config and defaults
+func parseFlags(fs *flag.FlagSet, args []string) {
+	for _, s := range settings {
+		name := strings.ReplaceAll(s.Key, "_", "-")
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		value, source := activeProfile.setting(s.Key)
+		if source == sourceDefault {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid %s '%s' from %s: %v\n", s.Key, value, describeSource(source, s.Key), err)
+			os.Exit(1)
+		}
+		if !secretSettings[s.Key] {
+			f.DefValue = value
+		}
+	}
+	fs.Parse(args)
+}
+
+// flagGiven reports whether a flag was passed on the command line itself, as opposed to
+// being set by parseFlags from the profile's settings
+func flagGiven(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func describeSource(source, key string) string {
+	switch source {
+	case sourceEnv:
+		return "$" + settingEnv(key)
+	case sourceConfig:
+		return configFile
+	}
+	return source
+}
+
+func printConfigUsage() {
+	fmt.Println("Configuration commands")
+	fmt.Println()
+	fmt.Println("Usage: launcher config <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  show  Show the effective settings and where each comes from")
+	fmt.Println()
+	fmt.Println("Settings are read from the profile's " + configFile + " and " + envPrefix + "<KEY> environment")
+	fmt.Println("variables. Command line flags override the environment, which overrides the file.")
+}
+
+// cmdConfig handles the config subcommand
+func cmdConfig(args []string) {
+	if len(args) < 1 {
+		printConfigUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "show":
+		cmdConfigShow(args[1:])
+	case "-help", "--help", "help":
+		printConfigUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n\n", args[0])
+		printConfigUsage()
+		os.Exit(1)
+	}
+}
+
+func cmdConfigShow(args []string) {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	fs.Usage = func() { printFlagUsage(fs, "launcher config show") }
+	fs.Parse(args)
+
+	p := activeProfile
+	path := p.configPath()
+	if _, err := os.Stat(path); err != nil {
+		path += " (not found)"
+	}
+	fmt.Printf("Profile:     %s\n", p.Name)
+	fmt.Printf("Config file: %s\n", path)
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tDESCRIPTION")
+	for _, s := range settings {
+		value, source := p.setting(s.Key)
+		if secretSettings[s.Key] && value != "" {
+			value = "********"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Key, value, describeSource(source, s.Key), s.Usage)
+	}
+	w.Flush()
+
+	var unknown []string
+	for key := range p.Config {
+		if _, ok := lookupSetting(key); !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		fmt.Println()
+		fmt.Printf("Warning: unknown keys in %s: %s\n", configFile, strings.Join(unknown, ", "))
+	}
+	fmt.Println()
+	fmt.Println("Flags given to a command override these values for that run.")
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/daemon"
+	"launcher/internal/obsws"
+	"launcher/internal/state"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// cmdDaemon runs the schedule in-process instead of registering crontab/Task Scheduler entries.
+// Every day it recomputes the sun times, creates the broadcast, and starts and ends it on time.
+func cmdDaemon(args []string) {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+
+	title := fs.String("title", "", "Stream title, a Go template rendered each day (default: the profile's title template, 'Marshall WX (MM/DD/YYYY)' unless changed)")
+	description := fs.String("description", "", "Stream description, a Go template with the same data as --title")
+	privacy := fs.String("privacy", "public", "Privacy status: public, unlisted, or private")
+	thumbnailPath := fs.String("thumbnail", "", "Image (JPEG or PNG, up to 2MB) to upload as each day's thumbnail instead of rendering one, or 'none'")
+	videoFlag := videoFlags(fs)
+
+	city := fs.String("city", "", "City for sunrise/sunset lookup")
+	startEvent := fs.String("time", "SUNRISE", "Start sun event: "+strings.Join(sunEvents, ", "))
+	endEvent := fs.String("end-time", "SUNSET", "End sun event")
+	startOffset := fs.Int("start-offset", defaultStartOffset, "Minutes offset from the start sun event")
+	endOffset := fs.Int("end-offset", defaultEndOffset, "Minutes offset from the end sun event")
+	sunSource := fs.String("sun-source", sunSourceNOAA, "Sun times source: 'noaa' (computed offline) or 'api' (api.sunrise-sunset.org)")
+
+	obsPath := fs.String("obs-path", "", "Custom path to OBS executable")
+	skipOBS := fs.Bool("skip-obs", false, "Skip starting OBS")
+	obsAddress := fs.String("obs-address", obsws.DefaultAddress, "obs-websocket server address (host:port)")
+	obsPassword := fs.String("obs-password", "", "obs-websocket password (default: $"+obsPasswordEnv+")")
+	obsTimeout := fs.Duration("obs-timeout", 2*time.Minute, "How long to wait for OBS to start streaming")
+	obsAssumeStreaming := fs.Bool("obs-assume-streaming", false, "Go live anyway if obs-websocket can't be reached after launching OBS")
+	liveTimeout := fs.Duration("live-timeout", DefaultGoLiveOptions().Timeout, "How long to wait for YouTube to see a healthy stream before going live")
+	pollInterval := fs.Duration("poll-interval", DefaultGoLiveOptions().PollInterval, "Initial delay between YouTube status checks (backs off up to 30s)")
+	gateFlag := weatherGateFlags(fs)
+	vodFlag := vodFlags(fs)
+
+	fs.Usage = func() { printFlagUsage(fs, "launcher daemon") }
+	parseFlags(fs, args)
+
+	gate, err := gateFlag()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	videoOpts := videoFlag()
+	vodOpts, err := vodFlag()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, anchor := range []string{*startEvent, *endEvent} {
+		if _, ok := parseSunEvent(anchor); !ok {
+			fmt.Fprintf(os.Stderr, "Error: daemon start/end times must be sun events (%s), got '%s'\n", strings.Join(sunEvents, ", "), anchor)
+			os.Exit(1)
+		}
+	}
+
+	baseDir := activeProfile.Dir
+
+	lat, lng, locationName, err := getLocation(*city)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting location: %v\n", err)
+		os.Exit(1)
+	}
+
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
+		os.Exit(1)
+	}
+	scheduler.OnTransition(func(broadcastID, status string) { recordTransition(baseDir, broadcastID, status) })
+
+	obsOpts := obsOptions{path: *obsPath, address: *obsAddress, password: *obsPassword, timeout: *obsTimeout, assumeStreaming: *obsAssumeStreaming}
+	if obsOpts.path == "" {
+		obsOpts.path = getOBSPath()
+	}
+	if obsOpts.password == "" {
+		obsOpts.password = os.Getenv(obsPasswordEnv)
+	}
+	goLiveOpts := DefaultGoLiveOptions()
+	goLiveOpts.Timeout = *liveTimeout
+	goLiveOpts.PollInterval = *pollInterval
+
+	cfg := daemon.Config{
+		Clock: daemon.RealClock,
+		Plan: func(day time.Time) (daemon.Window, error) {
+			sunTimes, err := getSunTimes(lat, lng, day, *sunSource)
+			if err != nil {
+				return daemon.Window{}, err
+			}
+			start, err := resolveScheduleTime(*startEvent, *startOffset, sunTimes)
+			if err != nil {
+				return daemon.Window{}, err
+			}
+			end, err := resolveScheduleTime(*endEvent, *endOffset, sunTimes)
+			if err != nil {
+				return daemon.Window{}, err
+			}
+			if !end.After(start) {
+				return daemon.Window{}, errors.New("stream end is not after stream start")
+			}
+			return daemon.Window{Start: start, End: end}, nil
+		},
+		Find: func(w daemon.Window) (*daemon.Existing, error) {
+			b, err := broadcastOn(baseDir, w.Start)
+			if err != nil || b == nil {
+				return nil, err
+			}
+			existing := &daemon.Existing{ID: b.ID}
+			switch b.Status {
+			case state.StatusLive:
+				existing.Started = true
+			case state.StatusComplete, state.StatusFailed, state.StatusSkipped, state.StatusCancelled:
+				existing.Done = true
+			}
+			return existing, nil
+		},
+		Schedule: func(ctx context.Context, w daemon.Window) (string, error) {
+			sunTimes, err := getSunTimes(lat, lng, w.Start, *sunSource)
+			if err != nil {
+				return "", err
+			}
+			plan := &streamPlan{Start: w.Start, End: w.End, Location: locationName, SunTimes: sunTimes}
+			text := newBroadcastText(plan)
+			streamTitle, streamDescription, err := renderBroadcastText(*title, *description, text)
+			if err != nil {
+				return "", err
+			}
+			playlist, err := renderText("playlist", videoOpts.Playlist, text)
+			if err != nil {
+				return "", err
+			}
+			broadcast, stream, err := scheduler.ScheduleStream(streamTitle, streamDescription, w.Start, *privacy)
+			if err != nil {
+				return "", err
+			}
+			recordScheduledBroadcast(baseDir, &state.Broadcast{
+				ID:           broadcast.Id,
+				Title:        streamTitle,
+				StreamID:     stream.Id,
+				PlannedStart: w.Start,
+				PlannedEnd:   w.End,
+			})
+			setThumbnail(scheduler, broadcast.Id, *thumbnailPath, streamTitle, plan)
+			setVideoOptions(scheduler, broadcast.Id, videoOpts, playlist, *privacy)
+			return broadcast.Id, nil
+		},
+		Start: func(ctx context.Context, broadcastID string) error {
+			skipReason, err := gate.wait(ctx, baseDir, broadcastID)
+			if err != nil {
+				return err
+			}
+			if skipReason != "" {
+				if err := scheduler.DeleteBroadcast(broadcastID); err != nil {
+					log.Printf("Could not delete skipped broadcast: %v", err)
+				}
+				return fmt.Errorf("%w: %s", daemon.ErrSkipped, skipReason)
+			}
+			if !*skipOBS {
+				if err := startOBSStream(obsOpts); err != nil {
+					recordFailure(baseDir, broadcastID, err)
+					return err
+				}
+			}
+			if err := scheduler.GoLive(broadcastID, goLiveOpts); err != nil {
+				recordFailure(baseDir, broadcastID, err)
+				return err
+			}
+			return nil
+		},
+		End: func(ctx context.Context, broadcastID string) error {
+			if err := scheduler.EndStream(broadcastID); err != nil {
+				return err
+			}
+			finishVOD(scheduler, baseDir, broadcastID, vodOpts)
+			return nil
+		},
+		Logf: log.Printf,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Stream daemon started for %s", locationName)
+	if err := daemon.Run(ctx, cfg); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Daemon stopped: %v", err)
+	}
+	log.Println("Stream daemon stopped")
+}
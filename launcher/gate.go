@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/state"
+	"launcher/internal/wx"
+	"strings"
+	"time"
+)
+
+// Weather gate modes
+const (
+	gateOff   = "off"
+	gateSkip  = "skip"
+	gateDelay = "delay"
+)
+
+// weatherGate decides from the weather station whether a broadcast goes live
+type weatherGate struct {
+	Mode   string
+	Limits wx.Limits
+	// Retry is how often a delayed stream rechecks the station, until MaxDelay has passed
+	Retry    time.Duration
+	MaxDelay time.Duration
+
+	// readings returns today's station readings (default: from the station_url setting)
+	readings func(ctx context.Context) ([]wx.Record, error)
+}
+
+// weatherGateFlags adds the weather gate options
+func weatherGateFlags(fs *flag.FlagSet) func() (*weatherGate, error) {
+	mode := fs.String("wx-gate", gateOff, "Check the weather station before going live: 'off', 'skip' (cancel the stream if conditions fail) or 'delay' (wait for them to pass)")
+	maxAge := fs.Duration("wx-max-age", 15*time.Minute, "Treat the station as down if its latest reading is older than this")
+	maxWind := fs.Float64("wx-max-wind", 0, "Highest acceptable sustained wind in mph (0: no limit)")
+	maxGust := fs.Float64("wx-max-gust", 0, "Highest acceptable gust in mph (0: no limit)")
+	window := fs.Duration("wx-window", 10*time.Minute, "How long the wind or gusts must stay above their limit to fail the check (0: the latest reading alone)")
+	retry := fs.Duration("wx-retry", 5*time.Minute, "How often a delayed stream rechecks the station")
+	maxDelay := fs.Duration("wx-max-delay", 2*time.Hour, "How long to delay before skipping the stream (never past its planned end)")
+	return func() (*weatherGate, error) {
+		switch *mode {
+		case gateOff, gateSkip, gateDelay:
+		default:
+			return nil, fmt.Errorf("unknown --wx-gate value '%s' (expected %s)", *mode, strings.Join([]string{gateOff, gateSkip, gateDelay}, ", "))
+		}
+		if *retry <= 0 {
+			return nil, errors.New("--wx-retry must be positive")
+		}
+		return &weatherGate{
+			Mode:     *mode,
+			Limits:   wx.Limits{MaxAge: *maxAge, MaxWind: *maxWind, MaxGust: *maxGust, Window: *window},
+			Retry:    *retry,
+			MaxDelay: *maxDelay,
+		}, nil
+	}
+}
+
+// check reads the station once and returns why the stream shouldn't go live, or ""
+func (g *weatherGate) check(ctx context.Context) string {
+	readings := g.readings
+	if readings == nil {
+		readings = func(ctx context.Context) ([]wx.Record, error) {
+			day, err := stationClient().Day(ctx, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			return day.Records, nil
+		}
+	}
+
+	records, err := readings(ctx)
+	if errors.Is(err, wx.ErrNoData) || (err == nil && len(records) == 0) {
+		return "the weather station has no readings today"
+	}
+	if err != nil {
+		return fmt.Sprintf("the weather station can't be read: %v", err)
+	}
+	return g.Limits.Check(records, time.Now())
+}
+
+// wait runs the gate for a broadcast, recording each decision in its history. It returns
+// the reason to skip the stream, or "" once it may go live. In delay mode it rechecks the
+// station until the conditions pass, MaxDelay has passed or the planned end is reached.
+func (g *weatherGate) wait(ctx context.Context, baseDir, broadcastID string) (string, error) {
+	if g == nil || g.Mode == gateOff {
+		return "", nil
+	}
+
+	deadline := time.Now().Add(g.MaxDelay)
+	if end := plannedEndOf(baseDir, broadcastID); !end.IsZero() && end.Before(deadline) {
+		deadline = end
+	}
+	for {
+		reason := g.check(ctx)
+		if reason == "" {
+			recordWeatherCheck(baseDir, broadcastID, state.DecisionGo, "")
+			fmt.Println("Weather check: go")
+			return "", nil
+		}
+		if g.Mode == gateSkip || !time.Now().Add(g.Retry).Before(deadline) {
+			recordWeatherCheck(baseDir, broadcastID, state.DecisionSkip, reason)
+			return reason, nil
+		}
+
+		recordWeatherCheck(baseDir, broadcastID, state.DecisionDelay, reason)
+		fmt.Printf("Weather check: delaying, %s (next check at %s)\n", reason, time.Now().Add(g.Retry).Format("15:04"))
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(g.Retry):
+		}
+	}
+}
+
+// skipStream deletes a broadcast the weather gate failed, so no empty VOD is left on the
+// channel. If it was the current broadcast, its start/end tasks are removed and a recurring
+// schedule moves on to its next occurrence; otherwise they belong to another broadcast.
+func skipStream(baseDir, execPath, broadcastID, reason string, current bool) error {
+	fmt.Printf("Weather check: skipping the stream, %s\n", reason)
+
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		return fmt.Errorf("error initializing YouTube scheduler: %w", err)
+	}
+	if err := scheduler.DeleteBroadcast(broadcastID); err != nil {
+		return err
+	}
+	fmt.Println("Broadcast deleted")
+	if !current {
+		fmt.Println("Start/end tasks left in place: they belong to the current broadcast")
+		return nil
+	}
+
+	for _, task := range []string{activeProfile.taskName(startTaskName), activeProfile.taskName(endTaskName)} {
+		if err := deleteScheduledTask(task); err != nil {
+			return fmt.Errorf("error removing %s task: %v", task, err)
+		}
+	}
+	fmt.Println("Start/end tasks removed")
+
+	plannedStart := plannedStartOf(baseDir, broadcastID)
+	if plannedStart.IsZero() {
+		plannedStart = time.Now()
+	}
+	return scheduleNextOccurrence(baseDir, execPath, plannedStart)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"launcher/internal/state"
+	"launcher/internal/wx"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// station serves one batch of readings per check, repeating the last one
+type station struct {
+	batches [][]wx.Record
+	errs    []error
+	checks  int
+}
+
+func (s *station) readings(context.Context) ([]wx.Record, error) {
+	i := s.checks
+	s.checks++
+	if i < len(s.errs) && s.errs[i] != nil {
+		return nil, s.errs[i]
+	}
+	if i >= len(s.batches) {
+		i = len(s.batches) - 1
+	}
+	return s.batches[i], nil
+}
+
+// windNow returns readings a minute apart ending now, with the given sustained winds
+func windNow(winds ...float64) []wx.Record {
+	now := time.Now()
+	var records []wx.Record
+	for i, w := range winds {
+		records = append(records, wx.Record{Time: now.Add(time.Duration(i-len(winds)+1) * time.Minute), Wind: w, Gust: w + 5})
+	}
+	return records
+}
+
+func TestWeatherGateWait(t *testing.T) {
+	calm := windNow(8, 9, 7)
+	windy := windNow(26, 28, 27)
+	limits := wx.Limits{MaxAge: 15 * time.Minute, MaxWind: 20, Window: 5 * time.Minute}
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		mode       string
+		maxDelay   time.Duration
+		plannedEnd time.Duration
+		station    *station
+		want       string
+		// decisions matches the recorded decisions, e.g. "delay delay go"
+		decisions string
+	}{
+		{name: "off", mode: gateOff, station: &station{batches: [][]wx.Record{windy}}, decisions: ""},
+		{name: "skip mode, go", mode: gateSkip, station: &station{batches: [][]wx.Record{calm}}, decisions: "go"},
+		{
+			name:      "skip mode, windy",
+			mode:      gateSkip,
+			station:   &station{batches: [][]wx.Record{windy}},
+			want:      "wind has stayed above 20.0 mph",
+			decisions: "skip",
+		},
+		{
+			name:      "skip mode, a single spike",
+			mode:      gateSkip,
+			station:   &station{batches: [][]wx.Record{windNow(8, 9, 30)}},
+			decisions: "go",
+		},
+		{
+			name:      "delay until it calms down",
+			mode:      gateDelay,
+			maxDelay:  time.Hour,
+			station:   &station{batches: [][]wx.Record{windy, windy, calm}},
+			decisions: "delay delay go",
+		},
+		{
+			name:      "delay while the station is down",
+			mode:      gateDelay,
+			maxDelay:  time.Hour,
+			station:   &station{batches: [][]wx.Record{nil, nil, calm}, errs: []error{down, wx.ErrNoData}},
+			decisions: "delay delay go",
+		},
+		{
+			name:      "delay runs out",
+			mode:      gateDelay,
+			maxDelay:  35 * time.Millisecond,
+			station:   &station{batches: [][]wx.Record{windy}},
+			want:      "wind has stayed above 20.0 mph",
+			decisions: "(delay )+skip",
+		},
+		{
+			name:       "planned end comes first",
+			mode:       gateDelay,
+			maxDelay:   time.Hour,
+			plannedEnd: 5 * time.Millisecond,
+			station:    &station{batches: [][]wx.Record{windy}},
+			want:       "wind has stayed above 20.0 mph",
+			decisions:  "skip",
+		},
+		{
+			name:      "station down",
+			mode:      gateSkip,
+			station:   &station{batches: [][]wx.Record{nil}, errs: []error{down}},
+			want:      "can't be read: connection refused",
+			decisions: "skip",
+		},
+		{
+			name:      "no readings yet",
+			mode:      gateSkip,
+			station:   &station{batches: [][]wx.Record{nil}},
+			want:      "has no readings today",
+			decisions: "skip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			err := openStateStore(baseDir).Update(func(st *state.State) error {
+				end := time.Now().Add(time.Hour)
+				if tt.plannedEnd > 0 {
+					end = time.Now().Add(tt.plannedEnd)
+				}
+				st.Add(&state.Broadcast{ID: "today", PlannedStart: time.Now(), PlannedEnd: end})
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gate := &weatherGate{Mode: tt.mode, Limits: limits, Retry: 10 * time.Millisecond, MaxDelay: tt.maxDelay, readings: tt.station.readings}
+			got, err := gate.wait(context.Background(), baseDir, "today")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" && got != "" || tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("skip reason = %q, want %q", got, tt.want)
+			}
+
+			var decisions []string
+			var status, current string
+			openStateStore(baseDir).View(func(st *state.State) error {
+				b := st.Find("today")
+				for _, c := range b.WeatherChecks {
+					decisions = append(decisions, c.Decision)
+				}
+				status, current = b.Status, st.Current
+				return nil
+			})
+			if !regexp.MustCompile("^" + tt.decisions + "$").MatchString(strings.Join(decisions, " ")) {
+				t.Errorf("recorded decisions %q, want %q", decisions, tt.decisions)
+			}
+			skipped := strings.HasSuffix(tt.decisions, state.DecisionSkip)
+			if skipped != (status == state.StatusSkipped) || skipped != (current == "") {
+				t.Errorf("after %q the status is %q and the current broadcast %q", tt.decisions, status, current)
+			}
+		})
+	}
+}
+
+func TestWeatherGateWaitCancelled(t *testing.T) {
+	baseDir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &station{batches: [][]wx.Record{windNow(26, 28, 27)}}
+	gate := &weatherGate{
+		Mode:     gateDelay,
+		Limits:   wx.Limits{MaxWind: 20},
+		Retry:    time.Hour,
+		MaxDelay: 2 * time.Hour,
+		readings: func(ctx context.Context) ([]wx.Record, error) {
+			defer cancel()
+			return s.readings(ctx)
+		},
+	}
+	if _, err := gate.wait(ctx, baseDir, "today"); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/state"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const stateFile = "state.json"
+
+// legacyBroadcastIDFile held the last scheduled broadcast ID before the state store existed
+const legacyBroadcastIDFile = "broadcast_id.txt"
+
+// openStateStore returns the broadcast history store, importing a leftover broadcast_id.txt first
+func openStateStore(baseDir string) *state.Store {
+	store := state.Open(filepath.Join(baseDir, stateFile))
+
+	legacyPath := filepath.Join(baseDir, legacyBroadcastIDFile)
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return store
+	}
+	legacyID := strings.TrimSpace(string(data))
+
+	err = store.Update(func(st *state.State) error {
+		if legacyID != "" && st.Find(legacyID) == nil {
+			st.Add(&state.Broadcast{ID: legacyID})
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not import %s: %v\n", legacyBroadcastIDFile, err)
+		return store
+	}
+	os.Remove(legacyPath)
+
+	return store
+}
+
+// recordScheduledBroadcast adds a new broadcast to the history and makes it the current one,
+// so 'stream start' and 'stream end' can run without -id
+func recordScheduledBroadcast(baseDir string, b *state.Broadcast) {
+	store := openStateStore(baseDir)
+	err := store.Update(func(st *state.State) error {
+		st.Add(b)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not record broadcast: %v\n", err)
+		return
+	}
+	fmt.Printf("Broadcast recorded in: %s\n", store.Path())
+}
+
+// recordTransition notes that a broadcast reached a lifecycle status. Failing to
+// record history must not stop a stream, so errors are only reported.
+func recordTransition(baseDir, broadcastID, status string) {
+	err := openStateStore(baseDir).Update(func(st *state.State) error {
+		st.Transition(broadcastID, status)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not record %s transition: %v\n", status, err)
+	}
+}
+
+func recordFailure(baseDir, broadcastID string, cause error) {
+	err := openStateStore(baseDir).Update(func(st *state.State) error {
+		st.Fail(broadcastID, cause)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not record failure: %v\n", err)
+	}
+}
+
+// recordCancellation marks a broadcast as cancelled and returns its planned start, if known
+func recordCancellation(baseDir, broadcastID string) time.Time {
+	var plannedStart time.Time
+	err := openStateStore(baseDir).Update(func(st *state.State) error {
+		b := st.Transition(broadcastID, state.StatusCancelled)
+		plannedStart = b.PlannedStart
+		if st.Current == broadcastID {
+			st.Current = ""
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not record cancellation: %v\n", err)
+	}
+	return plannedStart
+}
+
+func recordReschedule(baseDir, broadcastID string, start, end time.Time) {
+	err := openStateStore(baseDir).Update(func(st *state.State) error {
+		b := st.Track(broadcastID)
+		b.PlannedStart = start
+		b.PlannedEnd = end
+		b.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not record new schedule: %v\n", err)
+	}
+}
+
+// plannedStartOf returns the recorded planned start of a broadcast, or the zero time
+func plannedStartOf(baseDir, broadcastID string) time.Time {
+	var plannedStart time.Time
+	openStateStore(baseDir).View(func(st *state.State) error {
+		if b := st.Find(broadcastID); b != nil {
+			plannedStart = b.PlannedStart
+		}
+		return nil
+	})
+	return plannedStart
+}
+
+// plannedEndOf returns the recorded planned end of a broadcast, or the zero time
+func plannedEndOf(baseDir, broadcastID string) time.Time {
+	var plannedEnd time.Time
+	openStateStore(baseDir).View(func(st *state.State) error {
+		if b := st.Find(broadcastID); b != nil {
+			plannedEnd = b.PlannedEnd
+		}
+		return nil
+	})
+	return plannedEnd
+}
+
+func recordWeatherCheck(baseDir, broadcastID, decision, reason string) {
+	err := openStateStore(baseDir).Update(func(st *state.State) error {
+		st.CheckWeather(broadcastID, decision, reason)
+		if decision == state.DecisionSkip && st.Current == broadcastID {
+			st.Current = ""
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not record weather check: %v\n", err)
+	}
+}
+
+// broadcastOn returns the most recently created broadcast planned to start on day's
+// calendar date, or nil
+func broadcastOn(baseDir string, day time.Time) (*state.Broadcast, error) {
+	var found *state.Broadcast
+	y, m, d := day.Date()
+	err := openStateStore(baseDir).View(func(st *state.State) error {
+		for _, b := range st.Broadcasts {
+			by, bm, bd := b.PlannedStart.In(day.Location()).Date()
+			if by == y && bm == m && bd == d && (found == nil || !b.CreatedAt.Before(found.CreatedAt)) {
+				found = b
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// resolveBroadcastID returns id, or the current broadcast from the state store if id is empty
+func resolveBroadcastID(baseDir, id string) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+
+	store := openStateStore(baseDir)
+	err := store.View(func(st *state.State) error {
+		id = st.Current
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", fmt.Errorf("no broadcast ID provided and none recorded in %s", store.Path())
+	}
+	return id, nil
+}
+
+func cmdStreamList(args []string) {
+	fs := flag.NewFlagSet("stream list", flag.ExitOnError)
+	limit := fs.Int("limit", 20, "Number of most recent broadcasts to show (0 for all)")
+	fs.Usage = func() { printFlagUsage(fs, "launcher stream list") }
+	fs.Parse(args)
+
+	baseDir := activeProfile.Dir
+
+	var broadcasts []*state.Broadcast
+	var current string
+	err := openStateStore(baseDir).View(func(st *state.State) error {
+		broadcasts = st.Broadcasts
+		current = st.Current
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading broadcast history: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(broadcasts) == 0 {
+		fmt.Println("No broadcasts recorded")
+		return
+	}
+	if *limit > 0 && len(broadcasts) > *limit {
+		broadcasts = broadcasts[len(broadcasts)-*limit:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ID\tSTATUS\tPLANNED START\tPLANNED END\tTITLE")
+	for i := len(broadcasts) - 1; i >= 0; i-- {
+		b := broadcasts[i]
+		marker := " "
+		if b.ID == current {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\n", marker, b.ID, b.Status, formatHistoryTime(b.PlannedStart), formatHistoryTime(b.PlannedEnd), b.Title)
+	}
+	w.Flush()
+}
+
+func cmdStreamShow(args []string) {
+	fs := flag.NewFlagSet("stream show", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: launcher stream show [<broadcast-id>]")
+		fmt.Println()
+		fmt.Println("Shows the recorded history of a broadcast (default: the current one).")
+	}
+	fs.Parse(args)
+
+	baseDir := activeProfile.Dir
+
+	id, err := resolveBroadcastID(baseDir, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var b *state.Broadcast
+	err = openStateStore(baseDir).View(func(st *state.State) error {
+		b = st.Find(id)
+		if b == nil {
+			return errors.New("broadcast not found in history: " + id)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Broadcast ID:  %s\n", b.ID)
+	fmt.Printf("Title:         %s\n", b.Title)
+	fmt.Printf("Status:        %s\n", b.Status)
+	if b.Error != "" {
+		fmt.Printf("Error:         %s\n", b.Error)
+	}
+	fmt.Printf("Stream ID:     %s\n", b.StreamID)
+	fmt.Printf("Planned start: %s\n", formatHistoryTime(b.PlannedStart))
+	fmt.Printf("Planned end:   %s\n", formatHistoryTime(b.PlannedEnd))
+	fmt.Printf("Created:       %s\n", formatHistoryTime(b.CreatedAt))
+	if len(b.Transitions) > 0 {
+		fmt.Println("Transitions:")
+		for _, t := range b.Transitions {
+			fmt.Printf("  %-10s %s\n", t.Status, formatHistoryTime(t.At))
+		}
+	}
+	if len(b.WeatherChecks) > 0 {
+		fmt.Println("Weather checks:")
+		for _, c := range b.WeatherChecks {
+			fmt.Printf("  %-10s %s  %s\n", c.Decision, formatHistoryTime(c.At), c.Reason)
+		}
+	}
+	fmt.Printf("Watch URL:     https://youtube.com/watch?v=%s\n", b.ID)
+}
+
+func formatHistoryTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func executableDir() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
+		os.Exit(1)
+	}
+	return filepath.Dir(execPath)
+}
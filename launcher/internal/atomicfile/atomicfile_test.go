@@ -0,0 +1,61 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wind.txt")
+
+	for _, content := range []string{"13.0 mph, 18 mph, SW", "9.5 mph, 12 mph, WSW"} {
+		if err := Write(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("file = %q, want %q", data, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the file (temp files left behind?)", len(entries))
+	}
+}
+
+func TestWritePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows only has a read-only bit")
+	}
+	dir := t.TempDir()
+	for _, perm := range []os.FileMode{0600, 0644} {
+		path := filepath.Join(dir, perm.String())
+		if err := Write(path, []byte("x"), perm); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != perm {
+			t.Errorf("mode = %s, want %s", info.Mode().Perm(), perm)
+		}
+	}
+}
+
+func TestWriteMissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "state.json")
+	if err := Write(path, []byte("{}"), 0644); err == nil {
+		t.Error("Write into a missing directory succeeded")
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/oauth2"
+)
+
+const bundleKind = "obs-launcher-auth-bundle"
+
+// Bundle carries everything a headless machine needs to call the YouTube API
+// without running the browser flow: the OAuth client (credentials.json) and
+// a token with its refresh token
+type Bundle struct {
+	Kind        string          `json:"kind"`
+	Credentials json.RawMessage `json:"credentials"`
+	Token       *oauth2.Token   `json:"token"`
+}
+
+// SealBundle encrypts a bundle with a passphrase, so the refresh token can be
+// carried on a USB stick or sent over chat
+func SealBundle(credentials []byte, tok *oauth2.Token, passphrase string) ([]byte, error) {
+	if !json.Valid(credentials) {
+		return nil, errors.New("credentials are not valid JSON")
+	}
+	if tok.RefreshToken == "" {
+		return nil, errors.New("token has no refresh token; run 'launcher auth login' again before exporting")
+	}
+
+	plaintext, err := json.Marshal(&Bundle{Kind: bundleKind, Credentials: credentials, Token: tok})
+	if err != nil {
+		return nil, err
+	}
+	key, err := newSealKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return seal(plaintext, key)
+}
+
+// OpenBundle decrypts a bundle made by SealBundle
+func OpenBundle(data []byte, passphrase string) (*Bundle, error) {
+	plaintext, _, err := unseal(data, nil, func() (string, error) { return passphrase, nil })
+	if err != nil {
+		return nil, err
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(plaintext, &b); err != nil || b.Kind != bundleKind {
+		return nil, errors.New("not an auth bundle")
+	}
+	if b.Token == nil || b.Token.RefreshToken == "" {
+		return nil, errors.New("auth bundle has no refresh token")
+	}
+	if len(b.Credentials) == 0 {
+		return nil, errors.New("auth bundle has no client credentials")
+	}
+	return &b, nil
+}
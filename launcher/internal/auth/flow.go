@@ -0,0 +1,277 @@
+// Package auth implements the OAuth 2.0 installed-app authorization flow
+// used to obtain YouTube API tokens.
+//
+// The default flow redirects the browser to a listener on 127.0.0.1 and
+// captures the authorization code automatically. Manual is a fallback for
+// machines without a browser: the user opens the URL elsewhere and pastes
+// back the address the browser was redirected to. Both use PKCE and a random
+// state value.
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// manualRedirectURL is used when no listener is started. Installed-app
+// clients accept any loopback address, so the browser ends up on a page that
+// fails to load but whose address still carries the code.
+const manualRedirectURL = "http://127.0.0.1/"
+
+var (
+	// ErrStateMismatch is returned when the redirect's state does not match the
+	// one sent with the authorization request
+	ErrStateMismatch = errors.New("authorization response state does not match the request")
+	// ErrNoCode is returned when the redirect carries neither a code nor an error
+	ErrNoCode = errors.New("authorization response has no code")
+)
+
+// AuthorizationError is an error reported by the authorization server on the redirect
+type AuthorizationError struct {
+	Code        string
+	Description string
+}
+
+func (e *AuthorizationError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("authorization failed: %s: %s", e.Code, e.Description)
+	}
+	return fmt.Sprintf("authorization failed: %s", e.Code)
+}
+
+type Flow struct {
+	Config *oauth2.Config
+
+	// OpenURL opens the authorization URL. It defaults to OpenBrowser. The
+	// URL is always printed too, so a failure here is not fatal.
+	OpenURL func(authURL string) error
+	// In and Out are used for prompts; they default to stdin and stdout
+	In  io.Reader
+	Out io.Writer
+}
+
+// callbackResult is what the loopback handler received
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// Loopback runs the authorization flow with a redirect to a listener on a
+// random 127.0.0.1 port, and exchanges the captured code for a token.
+func (f *Flow) Loopback(ctx context.Context) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start local listener: %v", err)
+	}
+	defer listener.Close()
+
+	config := *f.Config
+	config.RedirectURL = fmt.Sprintf("http://%s/", listener.Addr().String())
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	results := make(chan callbackResult, 1)
+	server := &http.Server{Handler: callbackHandler(state, results)}
+	go server.Serve(listener)
+	defer func() {
+		// Let the browser finish loading the page saying how authorization went
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	authURL := authCodeURL(&config, state, verifier)
+	out := f.out()
+	fmt.Fprintln(out, "Opening your browser to authorize access to YouTube.")
+	fmt.Fprintln(out, "If it doesn't open, visit this URL:")
+	fmt.Fprintf(out, "\n%s\n\n", authURL)
+
+	openURL := f.OpenURL
+	if openURL == nil {
+		openURL = OpenBrowser
+	}
+	if err := openURL(authURL); err != nil {
+		fmt.Fprintf(out, "Could not open browser: %v\n", err)
+	}
+	fmt.Fprintln(out, "Waiting for authorization...")
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	return exchange(ctx, &config, result.code, verifier)
+}
+
+// Manual runs the authorization flow without a listener: the user visits the
+// URL on any machine and pastes back the address they were redirected to.
+func (f *Flow) Manual(ctx context.Context) (*oauth2.Token, error) {
+	config := *f.Config
+	config.RedirectURL = manualRedirectURL
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL := authCodeURL(&config, state, verifier)
+	out := f.out()
+	fmt.Fprintln(out, "Step 1: Visit this URL in a browser on any machine:")
+	fmt.Fprintf(out, "\n%s\n\n", authURL)
+	fmt.Fprintln(out, "Step 2: After authorizing, the browser is sent to a page on 127.0.0.1 that won't load.")
+	fmt.Fprintln(out, "Step 3: Copy that page's full address from the address bar and paste it below.")
+	fmt.Fprintln(out)
+	fmt.Fprint(out, "Redirected URL: ")
+
+	in := f.In
+	if in == nil {
+		in = os.Stdin
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("failed to read redirected URL: %v", err)
+	}
+
+	code, err := parseRedirect(strings.TrimSpace(line), state)
+	if err != nil {
+		return nil, err
+	}
+	return exchange(ctx, &config, code, verifier)
+}
+
+func (f *Flow) out() io.Writer {
+	if f.Out == nil {
+		return os.Stdout
+	}
+	return f.Out
+}
+
+// callbackHandler serves the loopback redirect and sends the outcome on
+// results. Only the first redirect with the matching state is accepted.
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		code, err := codeFromQuery(r.URL.Query(), state)
+		if errors.Is(err, ErrStateMismatch) {
+			// Not our request; don't let it end the flow
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case results <- callbackResult{code: code, err: err}:
+		default:
+			http.Error(w, "authorization already completed", http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<html><body><h1>Authorization failed</h1><p>%s</p></body></html>", html.EscapeString(err.Error()))
+			return
+		}
+		fmt.Fprint(w, "<html><body><h1>Authorization complete</h1><p>You can close this window and return to the launcher.</p></body></html>")
+	})
+}
+
+// parseRedirect extracts the code from a pasted redirect URL. A bare code is
+// accepted too, though its state can't be checked.
+func parseRedirect(input, state string) (string, error) {
+	if input == "" {
+		return "", ErrNoCode
+	}
+	if !strings.Contains(input, "?") {
+		return input, nil
+	}
+
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse redirected URL: %v", err)
+	}
+	return codeFromQuery(u.Query(), state)
+}
+
+func codeFromQuery(q url.Values, state string) (string, error) {
+	if q.Get("state") != state {
+		return "", ErrStateMismatch
+	}
+	if e := q.Get("error"); e != "" {
+		return "", &AuthorizationError{Code: e, Description: q.Get("error_description")}
+	}
+	code := q.Get("code")
+	if code == "" {
+		return "", ErrNoCode
+	}
+	return code, nil
+}
+
+// authCodeURL asks for offline access with a consent prompt, so a refresh
+// token is issued every time, and lets the user pick which account to use
+func authCodeURL(config *oauth2.Config, state, verifier string) string {
+	return config.AuthCodeURL(state,
+		oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("prompt", "select_account consent"),
+		oauth2.S256ChallengeOption(verifier),
+	)
+}
+
+func exchange(ctx context.Context, config *oauth2.Config, code, verifier string) (*oauth2.Token, error) {
+	tok, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve token: %v", err)
+	}
+	return tok, nil
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OpenBrowser opens target in the user's default browser
+func OpenBrowser(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	case "darwin":
+		cmd = exec.Command("open", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,368 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeTokenServer is an OAuth token endpoint that only issues a token for the code
+// it handed out, with a PKCE verifier matching the challenge of the authorization request
+type fakeTokenServer struct {
+	server *httptest.Server
+	code   string
+
+	mu        sync.Mutex
+	challenge string
+	exchanges int
+}
+
+func newFakeTokenServer(t *testing.T) *fakeTokenServer {
+	f := &fakeTokenServer{code: "4/test-code"}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveToken))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeTokenServer) config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   "https://accounts.example.com/o/oauth2/auth",
+			TokenURL:  f.server.URL + "/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{"https://www.googleapis.com/auth/youtube"},
+	}
+}
+
+func (f *fakeTokenServer) serveToken(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.exchanges++
+
+	fail := func(code, description string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": description})
+	}
+	if err := r.ParseForm(); err != nil {
+		fail("invalid_request", err.Error())
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != f.code {
+		fail("invalid_grant", "Malformed auth code.")
+		return
+	}
+	verifier := r.PostForm.Get("code_verifier")
+	if verifier == "" {
+		fail("invalid_grant", "Missing code verifier.")
+		return
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+		fail("invalid_grant", "Invalid code verifier.")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  "access-token",
+		"refresh_token": "refresh-token",
+		"token_type":    "Bearer",
+		"expires_in":    3599,
+	})
+}
+
+// authorize plays the browser and the authorization server's consent page: it records
+// the PKCE challenge and returns the redirect back to the launcher with the given query
+func (f *fakeTokenServer) authorize(t *testing.T, authURL string, query func(state string) url.Values) (*url.URL, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("access_type") != "offline" {
+		t.Errorf("authorization URL is missing PKCE or offline access: %s", authURL)
+	}
+	f.mu.Lock()
+	f.challenge = q.Get("code_challenge")
+	f.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		return nil, err
+	}
+	redirect.RawQuery = query(q.Get("state")).Encode()
+	return redirect, nil
+}
+
+func codeQuery(code string) func(state string) url.Values {
+	return func(state string) url.Values {
+		return url.Values{"state": {state}, "code": {code}}
+	}
+}
+
+// get requests a redirect the way the browser would, returning the status code
+func get(u *url.URL) (int, string, error) {
+	resp, err := http.Get(u.String())
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), nil
+}
+
+func TestLoopback(t *testing.T) {
+	f := newFakeTokenServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var statuses []int
+	browserDone := make(chan struct{})
+	flow := &Flow{
+		Config: f.config(),
+		Out:    io.Discard,
+		OpenURL: func(authURL string) error {
+			// The browser delivers the redirect once the flow is waiting for it
+			go func() {
+				defer close(browserDone)
+				// A redirect with someone else's state is turned away without ending the flow
+				forged, err := f.authorize(t, authURL, func(string) url.Values {
+					return url.Values{"state": {"forged"}, "code": {f.code}}
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				status, _, err := get(forged)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				statuses = append(statuses, status)
+
+				redirect, err := f.authorize(t, authURL, codeQuery(f.code))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				status, body, err := get(redirect)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				statuses = append(statuses, status)
+				if !strings.Contains(body, "Authorization complete") {
+					t.Errorf("callback page = %q", body)
+				}
+			}()
+			return nil
+		},
+	}
+
+	tok, err := flow.Loopback(ctx)
+	if err != nil {
+		t.Fatalf("Loopback: %v", err)
+	}
+	if tok.AccessToken != "access-token" || tok.RefreshToken != "refresh-token" {
+		t.Errorf("token = %+v", tok)
+	}
+
+	<-browserDone
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(statuses) != 2 || statuses[0] != http.StatusBadRequest || statuses[1] != http.StatusOK {
+		t.Errorf("callback statuses = %v, want [400 200]", statuses)
+	}
+	if f.exchanges != 1 {
+		t.Errorf("%d token exchanges, want 1", f.exchanges)
+	}
+}
+
+func TestLoopbackAuthorizationError(t *testing.T) {
+	f := newFakeTokenServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	browserDone := make(chan struct{})
+	flow := &Flow{
+		Config: f.config(),
+		Out:    io.Discard,
+		OpenURL: func(authURL string) error {
+			go func() {
+				defer close(browserDone)
+				redirect, err := f.authorize(t, authURL, func(state string) url.Values {
+					return url.Values{"state": {state}, "error": {"access_denied"}}
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if status, _, err := get(redirect); err != nil || status != http.StatusBadRequest {
+					t.Errorf("callback = %d, %v; want 400", status, err)
+				}
+			}()
+			return nil
+		},
+	}
+
+	_, err := flow.Loopback(ctx)
+	var authErr *AuthorizationError
+	if !errors.As(err, &authErr) || authErr.Code != "access_denied" {
+		t.Fatalf("Loopback error = %v, want an access_denied AuthorizationError", err)
+	}
+	<-browserDone
+	if f.exchanges != 0 {
+		t.Errorf("%d token exchanges after a denied authorization, want 0", f.exchanges)
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	const state = "expected-state"
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		// wantResult is whether the flow receives a result, and wantCode/wantErr what it is
+		wantResult bool
+		wantCode   string
+		wantErr    error
+	}{
+		{"code", "/?state=expected-state&code=abc", http.StatusOK, true, "abc", nil},
+		{"state mismatch", "/?state=other&code=abc", http.StatusBadRequest, false, "", nil},
+		{"missing state", "/?code=abc", http.StatusBadRequest, false, "", nil},
+		{"no code", "/?state=expected-state", http.StatusBadRequest, true, "", ErrNoCode},
+		{"other path", "/favicon.ico?state=expected-state&code=abc", http.StatusNotFound, false, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make(chan callbackResult, 1)
+			rec := httptest.NewRecorder()
+			callbackHandler(state, results).ServeHTTP(rec, httptest.NewRequest("GET", tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			select {
+			case result := <-results:
+				if !tt.wantResult {
+					t.Fatalf("flow received %+v, want nothing", result)
+				}
+				if result.code != tt.wantCode || !errors.Is(result.err, tt.wantErr) {
+					t.Errorf("result = %q, %v; want %q, %v", result.code, result.err, tt.wantCode, tt.wantErr)
+				}
+			default:
+				if tt.wantResult {
+					t.Fatal("flow received nothing")
+				}
+			}
+		})
+	}
+}
+
+func TestCallbackHandlerOnlyOnce(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := callbackHandler("s", results)
+
+	for i, want := range []int{http.StatusOK, http.StatusConflict} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/?state=s&code=abc", nil))
+		if rec.Code != want {
+			t.Errorf("redirect %d: status = %d, want %d", i+1, rec.Code, want)
+		}
+	}
+}
+
+func TestExchangeMissingVerifier(t *testing.T) {
+	f := newFakeTokenServer(t)
+	f.challenge = "challenge-from-the-authorization-request"
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, verifier := range []string{"", "not-the-verifier"} {
+		_, err := exchange(ctx, f.config(), f.code, verifier)
+		if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+			t.Errorf("exchange with verifier %q: error = %v, want invalid_grant", verifier, err)
+		}
+	}
+}
+
+func TestManual(t *testing.T) {
+	f := newFakeTokenServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The pasted address is only known once the authorization URL is printed
+	pr, pw := io.Pipe()
+	out := &urlWatcher{found: make(chan string, 1)}
+	go func() {
+		authURL := <-out.found
+		redirect, err := f.authorize(t, authURL, codeQuery(f.code))
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		io.WriteString(pw, redirect.String()+"\n")
+	}()
+
+	flow := &Flow{Config: f.config(), In: pr, Out: out}
+	tok, err := flow.Manual(ctx)
+	if err != nil {
+		t.Fatalf("Manual: %v", err)
+	}
+	if tok.AccessToken != "access-token" {
+		t.Errorf("token = %+v", tok)
+	}
+}
+
+// urlWatcher is an Out that reports the first URL printed
+type urlWatcher struct {
+	found chan string
+	sent  bool
+}
+
+func (w *urlWatcher) Write(p []byte) (int, error) {
+	for _, field := range strings.Fields(string(p)) {
+		if !w.sent && strings.HasPrefix(field, "https://") {
+			w.found <- field
+			w.sent = true
+		}
+	}
+	return len(p), nil
+}
+
+func TestParseRedirect(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantCode string
+		wantErr  error
+	}{
+		{"http://127.0.0.1/?state=s&code=abc&scope=youtube", "abc", nil},
+		{"abc", "abc", nil},
+		{"", "", ErrNoCode},
+		{"http://127.0.0.1/?state=t&code=abc", "", ErrStateMismatch},
+		{"http://127.0.0.1/?state=s", "", ErrNoCode},
+	}
+	for _, tt := range tests {
+		code, err := parseRedirect(tt.input, "s")
+		if code != tt.wantCode || !errors.Is(err, tt.wantErr) {
+			t.Errorf("parseRedirect(%q) = %q, %v; want %q, %v", tt.input, code, err, tt.wantCode, tt.wantErr)
+		}
+	}
+}
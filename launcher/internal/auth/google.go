@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Google endpoints for token introspection and revocation
+var (
+	TokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+	RevokeURL    = "https://oauth2.googleapis.com/revoke"
+)
+
+// TokenInfo is what Google reports about an access token
+type TokenInfo struct {
+	Scopes []string
+	Expiry time.Time
+}
+
+// FetchTokenInfo looks up the scopes and expiry of an access token
+func FetchTokenInfo(ctx context.Context, accessToken string) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", TokenInfoURL+"?access_token="+url.QueryEscape(accessToken), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch token info: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token info: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token info returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	// Numbers are sent as strings
+	var raw struct {
+		Scope string `json:"scope"`
+		Exp   string `json:"exp"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse token info: %v", err)
+	}
+
+	info := &TokenInfo{Scopes: strings.Fields(raw.Scope)}
+	if exp, err := strconv.ParseInt(raw.Exp, 10, 64); err == nil {
+		info.Expiry = time.Unix(exp, 0)
+	}
+	return info, nil
+}
+
+// Revoke revokes a refresh or access token. Revoking a refresh token also
+// invalidates the access tokens issued from it.
+func Revoke(ctx context.Context, token string) error {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, "POST", RevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("revoke returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
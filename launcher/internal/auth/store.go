@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoToken is returned by TokenStore.Load when no token has been saved
+var ErrNoToken = errors.New("no token saved")
+
+// TokenStore is where the OAuth token is kept between runs
+type TokenStore interface {
+	// Load returns the saved token, or ErrNoToken
+	Load() (*oauth2.Token, error)
+	Save(tok *oauth2.Token) error
+	// Delete removes the saved token. Deleting a missing token is not an error.
+	Delete() error
+	// String describes where the token is kept, for status output
+	String() string
+}
+
+// FileStore keeps the token as plaintext JSON, readable only by the current user
+type FileStore struct {
+	Path string
+}
+
+func (s *FileStore) Load() (*oauth2.Token, error) {
+	info, err := os.Stat(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Tighten files written before permissions were enforced
+	if info.Mode().Perm()&0077 != 0 {
+		if err := os.Chmod(s.Path, 0600); err != nil {
+			return nil, fmt.Errorf("failed to restrict token file permissions: %v", err)
+		}
+	}
+	return ReadTokenFile(s.Path)
+}
+
+func (s *FileStore) Save(tok *oauth2.Token) error {
+	return WriteTokenFile(s.Path, tok)
+}
+
+func (s *FileStore) Delete() error {
+	return removeIfExists(s.Path)
+}
+
+func (s *FileStore) String() string {
+	return "file " + s.Path
+}
+
+// scrypt parameters for new encrypted files (the recommended interactive setting)
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// sealedBox is the on-disk format of data encrypted with a passphrase
+type sealedBox struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// sealKey is a key derived from a passphrase, with the scrypt salt and parameters that produced it
+type sealKey struct {
+	salt    []byte
+	n, r, p int
+	key     []byte
+}
+
+// newSealKey derives a key for a new file, with a fresh salt
+func newSealKey(passphrase string) (*sealKey, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+	return deriveSealKey(passphrase, salt, scryptN, scryptR, scryptP)
+}
+
+func deriveSealKey(passphrase string, salt []byte, n, r, p int) (*sealKey, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+	return &sealKey{salt: salt, n: n, r: r, p: p, key: key}, nil
+}
+
+// opens reports whether k was derived with box's salt and parameters
+func (k *sealKey) opens(box *sealedBox) bool {
+	return k != nil && string(k.salt) == string(box.Salt) && k.n == box.N && k.r == box.R && k.p == box.P
+}
+
+// seal encrypts plaintext with AES-256-GCM under k. Every call uses a new random nonce.
+func seal(plaintext []byte, k *sealKey) ([]byte, error) {
+	box := sealedBox{Version: 1, KDF: "scrypt", N: k.n, R: k.r, P: k.p, Salt: k.salt}
+	gcm, err := newGCM(k.key)
+	if err != nil {
+		return nil, err
+	}
+	box.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(box.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	box.Ciphertext = gcm.Seal(nil, box.Nonce, plaintext, nil)
+
+	return json.MarshalIndent(box, "", "  ")
+}
+
+// unseal reverses seal. The passphrase is only asked for, and the key only derived, when
+// cached wasn't derived for data; the key that opened data is returned for reuse.
+func unseal(data []byte, cached *sealKey, passphrase func() (string, error)) ([]byte, *sealKey, error) {
+	var box sealedBox
+	if err := json.Unmarshal(data, &box); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse encrypted data: %v", err)
+	}
+	if box.Version != 1 || box.KDF != "scrypt" {
+		return nil, nil, errors.New("unsupported encryption format")
+	}
+
+	k := cached
+	if !k.opens(&box) {
+		pass, err := passphrase()
+		if err != nil {
+			return nil, nil, err
+		}
+		if k, err = deriveSealKey(pass, box.Salt, box.N, box.R, box.P); err != nil {
+			return nil, nil, err
+		}
+	}
+	gcm, err := newGCM(k.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := gcm.Open(nil, box.Nonce, box.Ciphertext, nil)
+	if err != nil {
+		return nil, nil, errors.New("failed to decrypt: wrong passphrase or corrupted file")
+	}
+	return plaintext, k, nil
+}
+
+// EncryptedFileStore keeps the token in a file encrypted with AES-256-GCM,
+// using a key derived from a passphrase with scrypt. The key is kept once
+// derived, so saving each refreshed token doesn't run scrypt again.
+type EncryptedFileStore struct {
+	Path string
+	// Passphrase is asked for the passphrase once per process. confirm is set
+	// when a new file is about to be created, so the caller can ask twice.
+	Passphrase func(confirm bool) (string, error)
+
+	mu         sync.Mutex
+	passphrase string
+	key        *sealKey
+}
+
+// getPassphrase must be called with s.mu held
+func (s *EncryptedFileStore) getPassphrase(confirm bool) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	if s.Passphrase == nil {
+		return "", errors.New("no passphrase available for the encrypted token")
+	}
+	passphrase, err := s.Passphrase(confirm)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("the token passphrase can't be empty")
+	}
+	s.passphrase = passphrase
+	return passphrase, nil
+}
+
+func (s *EncryptedFileStore) Load() (*oauth2.Token, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plaintext, key, err := unseal(data, s.key, func() (string, error) { return s.getPassphrase(false) })
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", s.Path, err)
+	}
+	s.key = key
+
+	tok := &oauth2.Token{}
+	if err := json.Unmarshal(plaintext, tok); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted token: %v", err)
+	}
+	return tok, nil
+}
+
+func (s *EncryptedFileStore) Save(tok *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil {
+		_, statErr := os.Stat(s.Path)
+		passphrase, err := s.getPassphrase(errors.Is(statErr, os.ErrNotExist))
+		if err != nil {
+			return err
+		}
+		if s.key, err = newSealKey(passphrase); err != nil {
+			return err
+		}
+	}
+
+	plaintext, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	data, err := seal(plaintext, s.key)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, data)
+}
+
+func (s *EncryptedFileStore) Delete() error {
+	return removeIfExists(s.Path)
+}
+
+func (s *EncryptedFileStore) String() string {
+	return "encrypted file " + s.Path
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// KeyringStore keeps the token in the OS keyring: the Secret Service (GNOME
+// Keyring, KWallet) on Linux, the Keychain on macOS and the Credential
+// Manager on Windows
+type KeyringStore struct {
+	Service string
+	User    string
+}
+
+func (s *KeyringStore) Load() (*oauth2.Token, error) {
+	secret, err := keyring.Get(s.Service, s.User)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token from keyring: %v", err)
+	}
+
+	tok := &oauth2.Token{}
+	if err := json.Unmarshal([]byte(secret), tok); err != nil {
+		return nil, fmt.Errorf("failed to parse token from keyring: %v", err)
+	}
+	return tok, nil
+}
+
+func (s *KeyringStore) Save(tok *oauth2.Token) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(s.Service, s.User, string(data)); err != nil {
+		return fmt.Errorf("failed to save token to keyring: %v", err)
+	}
+	return nil
+}
+
+func (s *KeyringStore) Delete() error {
+	err := keyring.Delete(s.Service, s.User)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete token from keyring: %v", err)
+	}
+	return nil
+}
+
+func (s *KeyringStore) String() string {
+	return fmt.Sprintf("keyring (service %s, account %s)", s.Service, s.User)
+}
+
+func removeIfExists(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestEncryptedFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "youtube_token.enc")
+	var prompts []bool
+	store := &EncryptedFileStore{Path: path, Passphrase: func(confirm bool) (string, error) {
+		prompts = append(prompts, confirm)
+		return "correct horse", nil
+	}}
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("Load before saving = %v, want ErrNoToken", err)
+	}
+	for _, access := range []string{"first", "second", "third"} {
+		if err := store.Save(&oauth2.Token{AccessToken: access, RefreshToken: "refresh"}); err != nil {
+			t.Fatal(err)
+		}
+		tok, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.AccessToken != access {
+			t.Errorf("Load = %q, want %q", tok.AccessToken, access)
+		}
+	}
+	// Asked once, with confirmation since the file was new
+	if len(prompts) != 1 || !prompts[0] {
+		t.Errorf("passphrase prompts = %v, want one confirmed prompt", prompts)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "refresh") {
+		t.Error("token file contains the refresh token in plaintext")
+	}
+
+	// A new process asks again, and only once
+	prompts = nil
+	reopened := &EncryptedFileStore{Path: path, Passphrase: store.Passphrase}
+	for i := 0; i < 2; i++ {
+		if tok, err := reopened.Load(); err != nil || tok.AccessToken != "third" {
+			t.Fatalf("Load after reopening = %v, %v", tok, err)
+		}
+	}
+	if err := reopened.Save(&oauth2.Token{AccessToken: "fourth"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != 1 || prompts[0] {
+		t.Errorf("passphrase prompts after reopening = %v, want one unconfirmed prompt", prompts)
+	}
+
+	wrong := &EncryptedFileStore{Path: path, Passphrase: func(bool) (string, error) { return "wrong", nil }}
+	if _, err := wrong.Load(); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Load with the wrong passphrase = %v", err)
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	credentials := []byte(`{"installed":{"client_id":"id"}}`)
+	data, err := SealBundle(credentials, &oauth2.Token{AccessToken: "a", RefreshToken: "r"}, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := OpenBundle(data, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Token.RefreshToken != "r" || string(b.Credentials) != string(credentials) {
+		t.Errorf("bundle = %+v", b)
+	}
+	if _, err := OpenBundle(data, "nope"); err == nil {
+		t.Error("OpenBundle with the wrong passphrase succeeded")
+	}
+}
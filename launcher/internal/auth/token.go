@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"launcher/internal/atomicfile"
+	"os"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ReauthRequiredError is returned by a persisting token source when Google
+// rejects the refresh token (invalid_grant): it was revoked, expired, or the
+// app's consent was withdrawn. Only a new login fixes it.
+type ReauthRequiredError struct {
+	Err error
+}
+
+func (e *ReauthRequiredError) Error() string {
+	return "YouTube authorization has expired or been revoked; run 'launcher auth login' to sign in again"
+}
+
+func (e *ReauthRequiredError) Unwrap() error {
+	return e.Err
+}
+
+// ReadTokenFile reads a token saved by WriteTokenFile
+func ReadTokenFile(path string) (*oauth2.Token, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tok := &oauth2.Token{}
+	if err := json.NewDecoder(f).Decode(tok); err != nil {
+		return nil, fmt.Errorf("failed to parse token file %s: %v", path, err)
+	}
+	return tok, nil
+}
+
+// WriteTokenFile saves tok to path, readable only by the current user
+func WriteTokenFile(path string, tok *oauth2.Token) error {
+	data, err := json.MarshalIndent(tok, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a 0600 file that replaces path atomically, so a crash
+// never leaves a truncated token behind
+func writeFileAtomic(path string, data []byte) error {
+	if err := atomicfile.Write(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token file: %v", err)
+	}
+	return nil
+}
+
+type persistingTokenSource struct {
+	base  oauth2.TokenSource
+	store TokenStore
+
+	mu   sync.Mutex
+	last *oauth2.Token
+	logf func(format string, args ...interface{})
+}
+
+// PersistingTokenSource returns a token source that refreshes tok with config
+// and saves every new token to store, so rotated refresh tokens and fresh
+// access tokens survive restarts. logf, if not nil, is told when a token
+// could not be saved.
+func PersistingTokenSource(ctx context.Context, config *oauth2.Config, tok *oauth2.Token, store TokenStore, logf func(format string, args ...interface{})) oauth2.TokenSource {
+	return &persistingTokenSource{
+		base:  config.TokenSource(ctx, tok),
+		store: store,
+		last:  tok,
+		logf:  logf,
+	}
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.base.Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return nil, &ReauthRequiredError{Err: err}
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last != nil && tok.AccessToken == s.last.AccessToken && tok.RefreshToken == s.last.RefreshToken {
+		return tok, nil
+	}
+
+	if err := s.store.Save(tok); err != nil {
+		if s.logf != nil {
+			s.logf("Warning: Could not save refreshed token: %v", err)
+		}
+		return tok, nil
+	}
+	s.last = tok
+	return tok, nil
+}
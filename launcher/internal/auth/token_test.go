@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// refreshServer answers refresh requests with the given token endpoint response
+func refreshServer(t *testing.T, status int, body map[string]interface{}) *oauth2.Config {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return &oauth2.Config{
+		ClientID: "client-id",
+		Endpoint: oauth2.Endpoint{TokenURL: server.URL, AuthStyle: oauth2.AuthStyleInParams},
+	}
+}
+
+func expiredToken() *oauth2.Token {
+	return &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+}
+
+func TestPersistingTokenSourceSaves(t *testing.T) {
+	config := refreshServer(t, http.StatusOK, map[string]interface{}{
+		"access_token": "new", "refresh_token": "rotated", "token_type": "Bearer", "expires_in": 3599,
+	})
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "token.json")}
+
+	tok, err := PersistingTokenSource(context.Background(), config, expiredToken(), store, t.Logf).Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "new" || saved.AccessToken != "new" || saved.RefreshToken != "rotated" {
+		t.Errorf("token = %+v, saved = %+v; want the rotated token saved", tok, saved)
+	}
+}
+
+func TestReauthRequiredThroughClient(t *testing.T) {
+	config := refreshServer(t, http.StatusBadRequest, map[string]interface{}{
+		"error": "invalid_grant", "error_description": "Token has been expired or revoked.",
+	})
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "token.json")}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("API called without a token")
+	}))
+	defer api.Close()
+
+	ctx := context.Background()
+	client := oauth2.NewClient(ctx, PersistingTokenSource(ctx, config, expiredToken(), store, t.Logf))
+	_, err := client.Get(api.URL)
+	// Callers wrap the error the way the YouTube scheduler does
+	err = fmt.Errorf("error initializing YouTube scheduler: %w", fmt.Errorf("error fetching broadcast: %w", err))
+
+	var reauth *ReauthRequiredError
+	if !errors.As(err, &reauth) {
+		t.Fatalf("error = %v, want a ReauthRequiredError in the chain", err)
+	}
+	if !strings.Contains(err.Error(), "launcher auth login") {
+		t.Errorf("error %q doesn't say how to sign in again", err)
+	}
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) || retrieveErr.ErrorCode != "invalid_grant" {
+		t.Errorf("error = %v, want the invalid_grant RetrieveError underneath", err)
+	}
+}
+
+func TestOtherRefreshErrors(t *testing.T) {
+	config := refreshServer(t, http.StatusInternalServerError, map[string]interface{}{"error": "internal_failure"})
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "token.json")}
+
+	_, err := PersistingTokenSource(context.Background(), config, expiredToken(), store, t.Logf).Token()
+	var reauth *ReauthRequiredError
+	if err == nil || errors.As(err, &reauth) {
+		t.Errorf("error = %v, want a plain refresh error", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Package daemon runs the daily stream schedule in-process, as an alternative
+// to registering crontab entries or Windows scheduled tasks.
+//
+// The loop itself knows nothing about YouTube or OBS: each day it asks Plan
+// for the stream window, then calls Schedule, Start and End at the right
+// times. Time is read through a Clock so the loop can be driven by a fake
+// clock in tests.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// maxSleep bounds a single wait so wall-clock jumps (suspend, NTP
+// corrections, DST) are noticed within a minute.
+const maxSleep = time.Minute
+
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RealClock is the system clock
+var RealClock Clock = realClock{}
+
+// Window is the planned stream start and end for one day
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Existing is a broadcast already created for a window, e.g. before the daemon was restarted
+type Existing struct {
+	ID string
+	// Started reports whether it already went live, so only End is left
+	Started bool
+	// Done reports whether the window is finished with: the broadcast ended, failed,
+	// was skipped or was cancelled
+	Done bool
+}
+
+// ErrSkipped is returned (wrapped) by Start when the day's stream was deliberately skipped
+var ErrSkipped = errors.New("stream skipped")
+
+type Config struct {
+	Clock Clock
+
+	// Plan returns the stream window for the calendar day of day
+	Plan func(day time.Time) (Window, error)
+	// Find returns the broadcast already created for a window, or nil. It is optional;
+	// without it every window gets a new broadcast.
+	Find func(w Window) (*Existing, error)
+	// Schedule creates the broadcast for a window and returns its ID
+	Schedule func(ctx context.Context, w Window) (string, error)
+	// Start brings the broadcast live
+	Start func(ctx context.Context, broadcastID string) error
+	// End completes the broadcast
+	End func(ctx context.Context, broadcastID string) error
+
+	Logf func(format string, args ...interface{})
+}
+
+// Run executes the daily schedule until ctx is cancelled. Cancellation is
+// only observed between steps, so a Start or End in progress is allowed to
+// finish, and a broadcast that is live is ended rather than left running.
+// Run returns ctx.Err() once cancelled.
+func Run(ctx context.Context, cfg Config) error {
+	if cfg.Clock == nil {
+		cfg.Clock = RealClock
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(string, ...interface{}) {}
+	}
+
+	day := cfg.Clock.Now()
+	for {
+		w, err := cfg.Plan(day)
+		if err != nil {
+			cfg.Logf("Could not plan stream for %s: %v", day.Format("2006-01-02"), err)
+			day = nextDay(day)
+			if err := sleepUntil(ctx, cfg.Clock, startOfDay(day)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !cfg.Clock.Now().Before(w.End) {
+			// Today's window is already over, but the daemon may have been down at its end
+			if existing := find(cfg, w); existing != nil && existing.Started && !existing.Done {
+				end(ctx, cfg, existing.ID)
+			}
+			day = nextDay(day)
+			continue
+		}
+
+		runWindow(ctx, cfg, w)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		day = nextDay(day)
+	}
+}
+
+// runWindow schedules, starts and ends one day's broadcast, picking up from an
+// existing broadcast for the window if there is one
+func runWindow(ctx context.Context, cfg Config, w Window) {
+	cfg.Logf("Next stream: %s - %s", w.Start.Format("2006-01-02 15:04"), w.End.Format("15:04"))
+
+	existing := find(cfg, w)
+	var broadcastID string
+	switch {
+	case existing != nil && existing.Done:
+		cfg.Logf("Broadcast %s for this window is already finished", existing.ID)
+		return
+	case existing != nil:
+		broadcastID = existing.ID
+		cfg.Logf("Resuming broadcast %s", broadcastID)
+	default:
+		var err error
+		broadcastID, err = cfg.Schedule(ctx, w)
+		if err != nil {
+			cfg.Logf("Error scheduling stream: %v", err)
+			return
+		}
+		cfg.Logf("Scheduled broadcast %s", broadcastID)
+	}
+
+	if existing == nil || !existing.Started {
+		if err := sleepUntil(ctx, cfg.Clock, w.Start); err != nil {
+			return
+		}
+		if err := cfg.Start(ctx, broadcastID); errors.Is(err, ErrSkipped) {
+			cfg.Logf("%v", err)
+			return
+		} else if err != nil {
+			cfg.Logf("Error starting stream: %v", err)
+			return
+		}
+	}
+
+	if err := sleepUntil(ctx, cfg.Clock, w.End); err != nil {
+		cfg.Logf("Stopping: ending broadcast %s early", broadcastID)
+	}
+	end(ctx, cfg, broadcastID)
+}
+
+// find looks up the window's existing broadcast. An error is logged and treated as
+// none, since a missed stream is worse than a duplicate one.
+func find(cfg Config, w Window) *Existing {
+	if cfg.Find == nil {
+		return nil
+	}
+	existing, err := cfg.Find(w)
+	if err != nil {
+		cfg.Logf("Could not look up an existing broadcast: %v", err)
+		return nil
+	}
+	return existing
+}
+
+// end completes a broadcast. It runs even when ctx is cancelled, so stopping the
+// daemon doesn't leave the broadcast live.
+func end(ctx context.Context, cfg Config, broadcastID string) {
+	if err := cfg.End(context.WithoutCancel(ctx), broadcastID); err != nil {
+		cfg.Logf("Error ending stream: %v", err)
+	}
+}
+
+// sleepUntil blocks until the clock reaches t or ctx is cancelled
+func sleepUntil(ctx context.Context, clock Clock, t time.Time) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		d := t.Sub(clock.Now())
+		if d <= 0 {
+			return nil
+		}
+		if d > maxSleep {
+			d = maxSleep
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(d):
+		}
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// nextDay returns noon of the following calendar day, which stays on the
+// right date across DST changes
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 12, 0, 0, 0, t.Location())
+}
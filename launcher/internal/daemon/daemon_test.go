@@ -0,0 +1,277 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock jumps forward by the requested duration whenever something waits on it,
+// so a whole day of the loop runs instantly
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// harness records the calls Run makes, with the fake time of each
+type harness struct {
+	t      *testing.T
+	clock  *fakeClock
+	cancel context.CancelFunc
+	calls  []string
+	nextID int
+
+	find     func(w Window) (*Existing, error)
+	start    func(id string) error
+	planErr  map[string]error
+	stopWhen func(call string) bool
+}
+
+func newHarness(t *testing.T, now time.Time) *harness {
+	return &harness{t: t, clock: &fakeClock{now: now}, planErr: map[string]error{}}
+}
+
+func (h *harness) record(format string, args ...interface{}) {
+	call := h.clock.now.Format("01-02 15:04") + " " + fmt.Sprintf(format, args...)
+	h.calls = append(h.calls, call)
+	if h.stopWhen != nil && h.stopWhen(call) {
+		h.cancel()
+	}
+}
+
+// window is 06:00 to 18:00 on each day
+func window(day time.Time) Window {
+	y, m, d := day.Date()
+	return Window{
+		Start: time.Date(y, m, d, 6, 0, 0, 0, day.Location()),
+		End:   time.Date(y, m, d, 18, 0, 0, 0, day.Location()),
+	}
+}
+
+func (h *harness) run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	defer cancel()
+
+	cfg := Config{
+		Clock: h.clock,
+		Plan: func(day time.Time) (Window, error) {
+			if err := h.planErr[day.Format("01-02")]; err != nil {
+				h.record("plan error")
+				return Window{}, err
+			}
+			return window(day), nil
+		},
+		Find: func(w Window) (*Existing, error) {
+			if h.find == nil {
+				return nil, nil
+			}
+			return h.find(w)
+		},
+		Schedule: func(ctx context.Context, w Window) (string, error) {
+			h.nextID++
+			id := fmt.Sprintf("b%d", h.nextID)
+			h.record("schedule %s", id)
+			return id, nil
+		},
+		Start: func(ctx context.Context, id string) error {
+			h.record("start %s", id)
+			if h.start != nil {
+				return h.start(id)
+			}
+			return nil
+		},
+		End: func(ctx context.Context, id string) error {
+			if ctx.Err() != nil {
+				h.t.Errorf("End(%s) got a cancelled context", id)
+			}
+			h.record("end %s", id)
+			return nil
+		},
+		Logf: h.t.Logf,
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- Run(ctx, cfg) }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(10 * time.Second):
+		h.t.Fatal("Run did not return")
+		return nil
+	}
+}
+
+func (h *harness) check(want ...string) {
+	h.t.Helper()
+	if got := strings.Join(h.calls, "\n"); got != strings.Join(want, "\n") {
+		h.t.Errorf("calls:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func day(d, hour, min int) time.Time {
+	return time.Date(2026, 10, d, hour, min, 0, 0, time.UTC)
+}
+
+func TestRunDailySchedule(t *testing.T) {
+	h := newHarness(t, day(16, 0, 0))
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "end b2") }
+
+	if err := h.run(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	h.check(
+		"10-16 00:00 schedule b1",
+		"10-16 06:00 start b1",
+		"10-16 18:00 end b1",
+		"10-16 18:00 schedule b2",
+		"10-17 06:00 start b2",
+		"10-17 18:00 end b2",
+	)
+}
+
+func TestRunAfterTodaysWindow(t *testing.T) {
+	h := newHarness(t, day(16, 19, 0))
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "end b1") }
+
+	h.run()
+	h.check(
+		"10-16 19:00 schedule b1",
+		"10-17 06:00 start b1",
+		"10-17 18:00 end b1",
+	)
+}
+
+func TestRunResumesScheduledBroadcast(t *testing.T) {
+	h := newHarness(t, day(16, 5, 0))
+	h.find = func(w Window) (*Existing, error) {
+		if w.Start.Day() == 16 {
+			return &Existing{ID: "earlier"}, nil
+		}
+		return nil, nil
+	}
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "end earlier") }
+
+	h.run()
+	h.check(
+		"10-16 06:00 start earlier",
+		"10-16 18:00 end earlier",
+	)
+}
+
+func TestRunResumesLiveBroadcast(t *testing.T) {
+	h := newHarness(t, day(16, 9, 30))
+	h.find = func(w Window) (*Existing, error) {
+		if w.Start.Day() == 16 {
+			return &Existing{ID: "live", Started: true}, nil
+		}
+		return nil, nil
+	}
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "end live") }
+
+	h.run()
+	h.check("10-16 18:00 end live")
+}
+
+func TestRunSkipsFinishedWindow(t *testing.T) {
+	h := newHarness(t, day(16, 9, 30))
+	h.find = func(w Window) (*Existing, error) {
+		if w.Start.Day() == 16 {
+			return &Existing{ID: "done", Done: true}, nil
+		}
+		return nil, nil
+	}
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "end b1") }
+
+	h.run()
+	h.check(
+		"10-16 09:30 schedule b1",
+		"10-17 06:00 start b1",
+		"10-17 18:00 end b1",
+	)
+}
+
+func TestRunEndsMissedEnd(t *testing.T) {
+	// The daemon was down at the end of a live broadcast's window
+	h := newHarness(t, day(16, 20, 0))
+	h.find = func(w Window) (*Existing, error) {
+		if w.Start.Day() == 16 {
+			return &Existing{ID: "stale", Started: true}, nil
+		}
+		return nil, nil
+	}
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "schedule b1") }
+
+	h.run()
+	h.check(
+		"10-16 20:00 end stale",
+		"10-16 20:00 schedule b1",
+	)
+}
+
+func TestRunEndsLiveBroadcastOnShutdown(t *testing.T) {
+	h := newHarness(t, day(16, 0, 0))
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "start b1") }
+
+	if err := h.run(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	h.check(
+		"10-16 00:00 schedule b1",
+		"10-16 06:00 start b1",
+		"10-16 06:00 end b1",
+	)
+}
+
+func TestRunShutdownBeforeStart(t *testing.T) {
+	h := newHarness(t, day(16, 0, 0))
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "schedule b1") }
+
+	h.run()
+	h.check("10-16 00:00 schedule b1")
+}
+
+func TestRunStartFailures(t *testing.T) {
+	h := newHarness(t, day(16, 0, 0))
+	h.start = func(id string) error {
+		if id == "b1" {
+			return fmt.Errorf("%w: too windy", ErrSkipped)
+		}
+		return errors.New("OBS is not streaming")
+	}
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "schedule b3") }
+
+	h.run()
+	// Neither a skipped nor a failed stream is ended; the next day goes ahead
+	h.check(
+		"10-16 00:00 schedule b1",
+		"10-16 06:00 start b1",
+		"10-16 06:00 schedule b2",
+		"10-17 06:00 start b2",
+		"10-17 06:00 schedule b3",
+	)
+}
+
+func TestRunPlanError(t *testing.T) {
+	h := newHarness(t, day(16, 3, 0))
+	h.planErr["10-16"] = errors.New("no sunrise")
+	h.stopWhen = func(call string) bool { return strings.HasSuffix(call, "schedule b1") }
+
+	h.run()
+	// The next day is planned from its midnight
+	h.check(
+		"10-16 03:00 plan error",
+		"10-17 00:00 schedule b1",
+	)
+}
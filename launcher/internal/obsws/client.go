@@ -0,0 +1,345 @@
+// Package obsws is a minimal client for the obs-websocket v5 protocol
+// (https://github.com/obsproject/obs-websocket/blob/master/docs/generated/protocol.md).
+//
+// It supports the Hello/Identify handshake with password authentication,
+// requests with their responses, and event delivery.
+package obsws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultAddress is where OBS listens when the WebSocket server is enabled
+const DefaultAddress = "localhost:4455"
+
+const (
+	subprotocol = "obswebsocket.json"
+	rpcVersion  = 1
+)
+
+// Message op codes
+const (
+	opHello           = 0
+	opIdentify        = 1
+	opIdentified      = 2
+	opEvent           = 5
+	opRequest         = 6
+	opRequestResponse = 7
+)
+
+// Event subscription flags passed to Dial
+const (
+	EventGeneral     = 1 << 0
+	EventConfig      = 1 << 1
+	EventScenes      = 1 << 2
+	EventInputs      = 1 << 3
+	EventTransitions = 1 << 4
+	EventFilters     = 1 << 5
+	EventOutputs     = 1 << 6
+	EventSceneItems  = 1 << 7
+	EventMediaInputs = 1 << 8
+	EventVendors     = 1 << 9
+	EventUi          = 1 << 10
+	EventAll         = EventGeneral | EventConfig | EventScenes | EventInputs | EventTransitions |
+		EventFilters | EventOutputs | EventSceneItems | EventMediaInputs | EventVendors | EventUi
+)
+
+// Output states reported in StreamStateChanged events
+const (
+	OutputStarting = "OBS_WEBSOCKET_OUTPUT_STARTING"
+	OutputStarted  = "OBS_WEBSOCKET_OUTPUT_STARTED"
+	OutputStopping = "OBS_WEBSOCKET_OUTPUT_STOPPING"
+	OutputStopped  = "OBS_WEBSOCKET_OUTPUT_STOPPED"
+)
+
+// RequestStatusOutputRunning is returned by StartStream when the stream output is already active
+const RequestStatusOutputRunning = 500
+
+var (
+	// ErrClosed is returned for requests made after the connection was closed
+	ErrClosed = errors.New("obs-websocket connection closed")
+	// ErrAuthenticationFailed is returned by Dial when OBS rejects the password
+	ErrAuthenticationFailed = errors.New("obs-websocket authentication failed")
+)
+
+type message struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d"`
+}
+
+type hello struct {
+	ObsWebSocketVersion string `json:"obsWebSocketVersion"`
+	RpcVersion          int    `json:"rpcVersion"`
+	Authentication      *struct {
+		Challenge string `json:"challenge"`
+		Salt      string `json:"salt"`
+	} `json:"authentication"`
+}
+
+type identify struct {
+	RpcVersion         int    `json:"rpcVersion"`
+	Authentication     string `json:"authentication,omitempty"`
+	EventSubscriptions int    `json:"eventSubscriptions"`
+}
+
+type request struct {
+	RequestType string      `json:"requestType"`
+	RequestID   string      `json:"requestId"`
+	RequestData interface{} `json:"requestData,omitempty"`
+}
+
+type requestResponse struct {
+	RequestType   string `json:"requestType"`
+	RequestID     string `json:"requestId"`
+	RequestStatus struct {
+		Result  bool   `json:"result"`
+		Code    int    `json:"code"`
+		Comment string `json:"comment"`
+	} `json:"requestStatus"`
+	ResponseData json.RawMessage `json:"responseData"`
+}
+
+// Event is an event pushed by OBS. Data holds the raw eventData object.
+type Event struct {
+	Type string          `json:"eventType"`
+	Data json.RawMessage `json:"eventData"`
+}
+
+// RequestError is returned when OBS reports a request as failed
+type RequestError struct {
+	RequestType string
+	Code        int
+	Comment     string
+}
+
+func (e *RequestError) Error() string {
+	if e.Comment != "" {
+		return fmt.Sprintf("obs-websocket %s failed (code %d): %s", e.RequestType, e.Code, e.Comment)
+	}
+	return fmt.Sprintf("obs-websocket %s failed (code %d)", e.RequestType, e.Code)
+}
+
+type Client struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	nextID  uint64
+
+	mu      sync.Mutex
+	pending map[string]chan *requestResponse
+	err     error
+
+	events chan Event
+	done   chan struct{}
+}
+
+// Dial connects to obs-websocket at addr (host:port), performs the
+// Hello/Identify handshake and subscribes to the given event categories.
+// password may be empty if authentication is disabled in OBS.
+func Dial(ctx context.Context, addr, password string, eventSubscriptions int) (*Client, error) {
+	u := url.URL{Scheme: "ws", Host: addr}
+	dialer := websocket.Dialer{Subprotocols: []string{subprotocol}}
+	conn, _, err := dialer.DialContext(ctx, u.String(), http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to obs-websocket at %s: %v", addr, err)
+	}
+
+	if err := handshake(ctx, conn, password, eventSubscriptions); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c := &Client{
+		conn:    conn,
+		pending: make(map[string]chan *requestResponse),
+		events:  make(chan Event, 64),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func handshake(ctx context.Context, conn *websocket.Conn, password string, eventSubscriptions int) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+		defer conn.SetReadDeadline(time.Time{})
+	}
+
+	var msg message
+	if err := conn.ReadJSON(&msg); err != nil {
+		return fmt.Errorf("failed to read obs-websocket Hello: %v", err)
+	}
+	if msg.Op != opHello {
+		return fmt.Errorf("expected obs-websocket Hello, got op %d", msg.Op)
+	}
+	var h hello
+	if err := json.Unmarshal(msg.D, &h); err != nil {
+		return fmt.Errorf("failed to parse obs-websocket Hello: %v", err)
+	}
+
+	id := identify{RpcVersion: rpcVersion, EventSubscriptions: eventSubscriptions}
+	if h.Authentication != nil {
+		if password == "" {
+			return fmt.Errorf("%w: a password is required", ErrAuthenticationFailed)
+		}
+		id.Authentication = authResponse(password, h.Authentication.Salt, h.Authentication.Challenge)
+	}
+
+	if err := writeMessage(conn, opIdentify, id); err != nil {
+		return fmt.Errorf("failed to send obs-websocket Identify: %v", err)
+	}
+
+	if err := conn.ReadJSON(&msg); err != nil {
+		// OBS closes the connection with code 4009 when authentication fails
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) && closeErr.Code == 4009 {
+			return fmt.Errorf("%w: wrong password", ErrAuthenticationFailed)
+		}
+		return fmt.Errorf("failed to read obs-websocket Identified: %v", err)
+	}
+	if msg.Op != opIdentified {
+		return fmt.Errorf("expected obs-websocket Identified, got op %d", msg.Op)
+	}
+	return nil
+}
+
+// authResponse computes base64(sha256(base64(sha256(password + salt)) + challenge))
+func authResponse(password, salt, challenge string) string {
+	secret := sha256.Sum256([]byte(password + salt))
+	secretB64 := base64.StdEncoding.EncodeToString(secret[:])
+	auth := sha256.Sum256([]byte(secretB64 + challenge))
+	return base64.StdEncoding.EncodeToString(auth[:])
+}
+
+func writeMessage(conn *websocket.Conn, op int, d interface{}) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return conn.WriteJSON(message{Op: op, D: data})
+}
+
+func (c *Client) readLoop() {
+	defer close(c.events)
+	defer close(c.done)
+
+	for {
+		var msg message
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			c.fail(err)
+			return
+		}
+
+		switch msg.Op {
+		case opEvent:
+			var ev Event
+			if err := json.Unmarshal(msg.D, &ev); err != nil {
+				continue
+			}
+			// Drop events nobody is reading rather than stall responses
+			select {
+			case c.events <- ev:
+			default:
+			}
+		case opRequestResponse:
+			var resp requestResponse
+			if err := json.Unmarshal(msg.D, &resp); err != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch, ok := c.pending[resp.RequestID]
+			delete(c.pending, resp.RequestID)
+			c.mu.Unlock()
+			if ok {
+				ch <- &resp
+			}
+		}
+	}
+}
+
+// fail records the connection error and releases every pending request
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// connection ends. Events are dropped if the channel's buffer is full.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// Request sends a request and waits for its response. requestData may be nil.
+// If out is non-nil, the response data is decoded into it.
+func (c *Client) Request(ctx context.Context, requestType string, requestData interface{}, out interface{}) error {
+	id := strconv.FormatUint(atomic.AddUint64(&c.nextID, 1), 10)
+	ch := make(chan *requestResponse, 1)
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err := writeMessage(c.conn, opRequest, request{RequestType: requestType, RequestID: id, RequestData: requestData})
+	c.writeMu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return fmt.Errorf("failed to send obs-websocket %s: %v", requestType, err)
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return ErrClosed
+		}
+		if !resp.RequestStatus.Result {
+			return &RequestError{RequestType: requestType, Code: resp.RequestStatus.Code, Comment: resp.RequestStatus.Comment}
+		}
+		if out != nil && len(resp.ResponseData) > 0 {
+			if err := json.Unmarshal(resp.ResponseData, out); err != nil {
+				return fmt.Errorf("failed to parse obs-websocket %s response: %v", requestType, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	c.writeMu.Lock()
+	c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.writeMu.Unlock()
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
@@ -0,0 +1,246 @@
+package obsws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeOBS is an in-process obs-websocket server. It performs the Hello/Identify
+// handshake and answers the stream requests, reporting output state changes as
+// StreamStateChanged events.
+type fakeOBS struct {
+	t        *testing.T
+	password string
+	server   *httptest.Server
+
+	mu         sync.Mutex
+	conn       *websocket.Conn
+	active     bool
+	requests   []string
+	subscribed int
+}
+
+const (
+	fakeSalt      = "lM1GncleQOaCu9lT1yeUZhFYnMjKFbdjkOPL0nVRcOQ="
+	fakeChallenge = "+IxH4CnCiqpX1rM9scsNynZzbOe4KhDeYcTNS3PDaeY="
+)
+
+func newFakeOBS(t *testing.T, password string) *fakeOBS {
+	f := &fakeOBS{t: t, password: password}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeOBS) addr() string {
+	return strings.TrimPrefix(f.server.URL, "http://")
+}
+
+func (f *fakeOBS) serve(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{subprotocol}}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		f.t.Errorf("upgrade: %v", err)
+		return
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != subprotocol {
+		f.t.Errorf("subprotocol = %q, want %q", conn.Subprotocol(), subprotocol)
+	}
+
+	h := hello{ObsWebSocketVersion: "5.4.2", RpcVersion: rpcVersion}
+	if f.password != "" {
+		h.Authentication = &struct {
+			Challenge string `json:"challenge"`
+			Salt      string `json:"salt"`
+		}{fakeChallenge, fakeSalt}
+	}
+	if err := writeMessage(conn, opHello, h); err != nil {
+		return
+	}
+
+	// The client hangs up here if it has no password to answer the challenge with
+	var msg message
+	if err := conn.ReadJSON(&msg); err != nil {
+		return
+	}
+	if msg.Op != opIdentify {
+		f.t.Errorf("expected Identify, got op %d", msg.Op)
+		return
+	}
+	var id identify
+	json.Unmarshal(msg.D, &id)
+	if f.password != "" && id.Authentication != authResponse(f.password, fakeSalt, fakeChallenge) {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4009, "Authentication failed."))
+		return
+	}
+	f.mu.Lock()
+	f.conn = conn
+	f.subscribed = id.EventSubscriptions
+	f.mu.Unlock()
+	if err := writeMessage(conn, opIdentified, map[string]int{"negotiatedRpcVersion": rpcVersion}); err != nil {
+		return
+	}
+
+	for {
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		if msg.Op != opRequest {
+			continue
+		}
+		var req request
+		json.Unmarshal(msg.D, &req)
+		f.mu.Lock()
+		f.requests = append(f.requests, req.RequestType)
+		active := f.active
+		f.mu.Unlock()
+
+		resp := requestResponse{RequestType: req.RequestType, RequestID: req.RequestID}
+		resp.RequestStatus.Result = true
+		resp.RequestStatus.Code = 100
+		var events []StreamStateChanged
+		switch req.RequestType {
+		case "GetStreamStatus":
+			resp.ResponseData, _ = json.Marshal(StreamStatus{OutputActive: active})
+		case "StartStream":
+			if active {
+				resp.RequestStatus.Result = false
+				resp.RequestStatus.Code = RequestStatusOutputRunning
+				break
+			}
+			f.mu.Lock()
+			f.active = true
+			f.mu.Unlock()
+			events = []StreamStateChanged{{false, OutputStarting}, {true, OutputStarted}}
+		default:
+			resp.RequestStatus.Result = false
+			resp.RequestStatus.Code = 204
+			resp.RequestStatus.Comment = "Your request type is not valid."
+		}
+		if err := writeMessage(conn, opRequestResponse, resp); err != nil {
+			return
+		}
+		for _, ev := range events {
+			data, _ := json.Marshal(ev)
+			writeMessage(conn, opEvent, Event{Type: "StreamStateChanged", Data: data})
+		}
+	}
+}
+
+// drop closes the identified connection from the server side
+func (f *fakeOBS) drop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn != nil {
+		f.conn.Close()
+	}
+}
+
+func dialFake(t *testing.T, f *fakeOBS, password string) *Client {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, f.addr(), password, EventOutputs)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestStartStream(t *testing.T) {
+	for _, password := range []string{"", "hunter2"} {
+		t.Run("password="+password, func(t *testing.T) {
+			f := newFakeOBS(t, password)
+			c := dialFake(t, f, password)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			status, err := c.GetStreamStatus(ctx)
+			if err != nil {
+				t.Fatalf("GetStreamStatus: %v", err)
+			}
+			if status.OutputActive {
+				t.Fatal("OutputActive = true before StartStream")
+			}
+			if err := c.StartStream(ctx); err != nil {
+				t.Fatalf("StartStream: %v", err)
+			}
+			if err := c.WaitForStreamState(ctx, OutputStarted); err != nil {
+				t.Fatalf("WaitForStreamState: %v", err)
+			}
+
+			// A second start is not an error
+			if err := c.StartStream(ctx); err != nil {
+				t.Fatalf("StartStream while active: %v", err)
+			}
+			if status, err = c.GetStreamStatus(ctx); err != nil || !status.OutputActive {
+				t.Fatalf("GetStreamStatus = %+v, %v; want active", status, err)
+			}
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if f.subscribed != EventOutputs {
+				t.Errorf("event subscriptions = %d, want %d", f.subscribed, EventOutputs)
+			}
+			want := []string{"GetStreamStatus", "StartStream", "StartStream", "GetStreamStatus"}
+			if strings.Join(f.requests, ",") != strings.Join(want, ",") {
+				t.Errorf("requests = %v, want %v", f.requests, want)
+			}
+		})
+	}
+}
+
+func TestDialAuthentication(t *testing.T) {
+	f := newFakeOBS(t, "hunter2")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, password := range []string{"", "wrong"} {
+		c, err := Dial(ctx, f.addr(), password, EventOutputs)
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			if c != nil {
+				c.Close()
+			}
+			t.Errorf("Dial with password %q: error = %v, want ErrAuthenticationFailed", password, err)
+		}
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	f := newFakeOBS(t, "")
+	c := dialFake(t, f, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := c.StopStream(ctx)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Code != 204 || reqErr.RequestType != "StopStream" {
+		t.Fatalf("StopStream error = %v, want a RequestError with code 204", err)
+	}
+}
+
+func TestClosedConnection(t *testing.T) {
+	f := newFakeOBS(t, "")
+	c := dialFake(t, f, "")
+	f.drop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.WaitForStreamState(ctx, OutputStarted); !errors.Is(err, ErrClosed) {
+		t.Fatalf("WaitForStreamState after close = %v, want ErrClosed", err)
+	}
+	if _, err := c.GetStreamStatus(ctx); !errors.Is(err, ErrClosed) {
+		t.Fatalf("GetStreamStatus after close = %v, want ErrClosed", err)
+	}
+}
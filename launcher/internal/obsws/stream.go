@@ -0,0 +1,78 @@
+package obsws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// StreamStatus is the response data of GetStreamStatus
+type StreamStatus struct {
+	OutputActive        bool    `json:"outputActive"`
+	OutputReconnecting  bool    `json:"outputReconnecting"`
+	OutputTimecode      string  `json:"outputTimecode"`
+	OutputDuration      float64 `json:"outputDuration"`
+	OutputCongestion    float64 `json:"outputCongestion"`
+	OutputBytes         int64   `json:"outputBytes"`
+	OutputSkippedFrames int64   `json:"outputSkippedFrames"`
+	OutputTotalFrames   int64   `json:"outputTotalFrames"`
+}
+
+// StreamStateChanged is the data of a StreamStateChanged event
+type StreamStateChanged struct {
+	OutputActive bool   `json:"outputActive"`
+	OutputState  string `json:"outputState"`
+}
+
+// GetStreamStatus returns the status of the stream output
+func (c *Client) GetStreamStatus(ctx context.Context) (*StreamStatus, error) {
+	var status StreamStatus
+	if err := c.Request(ctx, "GetStreamStatus", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// StartStream starts the stream output. It is not an error if the output is already running.
+func (c *Client) StartStream(ctx context.Context) error {
+	err := c.Request(ctx, "StartStream", nil, nil)
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.Code == RequestStatusOutputRunning {
+		return nil
+	}
+	return err
+}
+
+// StopStream stops the stream output
+func (c *Client) StopStream(ctx context.Context) error {
+	return c.Request(ctx, "StopStream", nil, nil)
+}
+
+// WaitForStreamState blocks until a StreamStateChanged event with the given
+// output state arrives. The client must be subscribed to EventOutputs.
+func (c *Client) WaitForStreamState(ctx context.Context, state string) error {
+	for {
+		select {
+		case ev, ok := <-c.events:
+			if !ok {
+				return ErrClosed
+			}
+			if ev.Type != "StreamStateChanged" {
+				continue
+			}
+			var changed StreamStateChanged
+			if err := json.Unmarshal(ev.Data, &changed); err != nil {
+				return fmt.Errorf("failed to parse StreamStateChanged event: %v", err)
+			}
+			if changed.OutputState == state {
+				return nil
+			}
+			if changed.OutputState == OutputStopped && state == OutputStarted {
+				return errors.New("OBS stream output stopped before it started")
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package overlay serves a wind overlay page for OBS browser sources. The page gets the
+// latest reading from a server-sent events stream; the same data is available as JSON.
+package overlay
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//go:embed static
+var static embed.FS
+
+// keepAlive is how often an idle event stream gets a comment, so proxies keep it open
+const keepAlive = 30 * time.Second
+
+// Reading is what the overlay shows
+type Reading struct {
+	Online bool `json:"online"`
+	// Reason says why the station is offline
+	Reason    string    `json:"reason,omitempty"`
+	Time      time.Time `json:"time,omitempty"`
+	Wind      float64   `json:"wind_mph"`
+	Gust      float64   `json:"gust_mph"`
+	Direction float64   `json:"direction_deg"`
+	Cardinal  string    `json:"cardinal"`
+	// Updated is when the station was last polled
+	Updated time.Time `json:"updated"`
+}
+
+// Server serves the overlay and publishes readings to connected pages
+type Server struct {
+	// CSS is served after the default style sheet, to restyle the overlay
+	CSS []byte
+
+	mu          sync.Mutex
+	current     Reading
+	subscribers map[chan Reading]struct{}
+}
+
+// Publish sends a reading to every connected page
+func (s *Server) Publish(r Reading) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = r
+	for ch := range s.subscribers {
+		// A page that isn't keeping up only needs the latest reading
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- r
+	}
+}
+
+func (s *Server) subscribe() (chan Reading, Reading) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan Reading]struct{})
+	}
+	ch := make(chan Reading, 1)
+	s.subscribers[ch] = struct{}{}
+	return ch, s.current
+}
+
+func (s *Server) unsubscribe(ch chan Reading) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, ch)
+}
+
+// Handler serves the overlay page at /, the reading at /wind.json and the event stream at /events
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveFile("static/index.html", "text/html; charset=utf-8"))
+	mux.HandleFunc("/overlay.js", s.serveFile("static/overlay.js", "text/javascript; charset=utf-8"))
+	mux.HandleFunc("/overlay.css", s.serveCSS)
+	mux.HandleFunc("/wind.json", s.serveJSON)
+	mux.HandleFunc("/events", s.serveEvents)
+	return mux
+}
+
+func (s *Server) serveFile(name, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" && "static"+r.URL.Path != name {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := static.ReadFile(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	}
+}
+
+func (s *Server) serveCSS(w http.ResponseWriter, r *http.Request) {
+	data, err := static.ReadFile("static/overlay.css")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Write(data)
+	if len(s.CSS) > 0 {
+		w.Write([]byte("\n/* Custom style */\n"))
+		w.Write(s.CSS)
+	}
+}
+
+func (s *Server) serveJSON(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	current := s.current
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(current)
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch, current := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	send := func(reading Reading) bool {
+		data, err := json.Marshal(reading)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	if !current.Updated.IsZero() && !send(current) {
+		return
+	}
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case reading := <-ch:
+			if !send(reading) {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Updates the overlay from the launcher's event stream
+(function () {
+  var overlay = document.getElementById("overlay");
+
+  function pad(n) {
+    return (n < 10 ? "0" : "") + n;
+  }
+
+  function formatTime(value) {
+    var t = new Date(value);
+    if (isNaN(t.getTime()) || t.getFullYear() < 2000) {
+      return "";
+    }
+    return t.getFullYear() + "/" + pad(t.getMonth() + 1) + "/" + pad(t.getDate()) + " " + pad(t.getHours()) + ":" + pad(t.getMinutes());
+  }
+
+  function show(reading) {
+    overlay.classList.toggle("offline", !reading.online);
+    document.getElementById("status").textContent = reading.online ? "" : "(" + (reading.reason || "offline") + ")";
+    document.getElementById("time").textContent = formatTime(reading.online ? reading.time : reading.updated);
+    if (!reading.online) {
+      document.getElementById("wind").textContent = "--";
+      document.getElementById("gust").textContent = "--";
+      document.getElementById("cardinal").textContent = "--";
+      return;
+    }
+    document.getElementById("wind").textContent = reading.wind_mph.toFixed(1);
+    document.getElementById("gust").textContent = Math.round(reading.gust_mph);
+    document.getElementById("cardinal").textContent = reading.cardinal;
+    // The station reports where the wind comes from; the arrow points where it blows
+    document.getElementById("arrow").style.transform = "rotate(" + (reading.direction_deg + 180) + "deg)";
+  }
+
+  function connect() {
+    var events = new EventSource("/events");
+    events.onmessage = function (e) {
+      show(JSON.parse(e.data));
+    };
+  }
+
+  fetch("/wind.json").then(function (r) { return r.json(); }).then(show).catch(function () {});
+  connect();
+})();
@@ -12,20 +12,20 @@ import (
 
 type GithubRelease struct {
 	TagName string `json:"tag_name"`
-	Assets []struct {
-		Name string `json:"name"`
+	Assets  []struct {
+		Name               string `json:"name"`
 		BrowserDownloadURL string `json:"browser_download_url"`
 	} `json:"assets"`
 }
 
 type Updater struct {
-	ApiUrl string
+	ApiUrl         string
 	CurrentTagName string
 }
 
 func NewUpdater(currentTagName string) *Updater {
 	return &Updater{
-		ApiUrl: "https://api.github.com/repos/matsuzen/obs-andy-jackson/releases",
+		ApiUrl:         "https://api.github.com/repos/matsuzen/obs-andy-jackson/releases",
 		CurrentTagName: currentTagName,
 	}
 }
@@ -45,25 +45,25 @@ func (u *Updater) GetLatestRelease() (*GithubRelease, error) {
 }
 
 func (u *Updater) Apply(release *GithubRelease) error {
-    if release.TagName == u.CurrentTagName {
-        return errors.New("Already up to date")
-    }
-
-    assetName := fmt.Sprintf("launcher-%s-%s", runtime.GOOS, runtime.GOARCH)
-    if runtime.GOOS == "windows" {
-        assetName += ".exe"
-    }
-
-    var downloadURL string
-    for _, asset := range release.Assets {
-        if asset.Name == assetName {
-            downloadURL = asset.BrowserDownloadURL
-          	break
-        }
-    }
-
-    execPath, _ := os.Executable()
-    tmpPath := execPath + ".new"
+	if release.TagName == u.CurrentTagName {
+		return errors.New("Already up to date")
+	}
+
+	assetName := fmt.Sprintf("launcher-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		assetName += ".exe"
+	}
+
+	var downloadURL string
+	for _, asset := range release.Assets {
+		if asset.Name == assetName {
+			downloadURL = asset.BrowserDownloadURL
+			break
+		}
+	}
+
+	execPath, _ := os.Executable()
+	tmpPath := execPath + ".new"
 
 	out, err := os.Create(tmpPath)
 	if err != nil {
@@ -82,16 +82,15 @@ func (u *Updater) Apply(release *GithubRelease) error {
 		return errors.New(fmt.Sprintf("Error copying new release to temp file: %s\n", err))
 	}
 
-    if runtime.GOOS == "windows" {
-        oldPath := execPath + ".old"
-        os.Rename(execPath, oldPath)
-        os.Rename(tmpPath, execPath)
-    } else {
-        os.Rename(tmpPath, execPath)
-        os.Chmod(execPath, 0755)
-    }
+	if runtime.GOOS == "windows" {
+		oldPath := execPath + ".old"
+		os.Rename(execPath, oldPath)
+		os.Rename(tmpPath, execPath)
+	} else {
+		os.Rename(tmpPath, execPath)
+		os.Chmod(execPath, 0755)
+	}
 
 	u.CurrentTagName = release.TagName
 	return nil
-  }
-
+}
@@ -0,0 +1,255 @@
+// Package solar computes sunrise, sunset and related solar events locally
+// using the NOAA solar position algorithm, so no network access is needed.
+//
+// The equations follow the NOAA Solar Calculator
+// (https://gml.noaa.gov/grad/solcalc/) and are accurate to within a minute
+// for latitudes between +/- 72 degrees.
+package solar
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Solar zenith angles (degrees) that define each event.
+const (
+	// ZenithSunrise accounts for atmospheric refraction (0.567 deg) and the
+	// radius of the solar disc.
+	ZenithSunrise      = 90.833
+	ZenithCivil        = 96.0
+	ZenithNautical     = 102.0
+	ZenithAstronomical = 108.0
+)
+
+var (
+	// ErrSunNeverRises is returned when the sun stays below the requested
+	// zenith angle for the whole day (polar night).
+	ErrSunNeverRises = errors.New("sun never rises above the horizon on this date")
+	// ErrSunNeverSets is returned when the sun stays above the requested
+	// zenith angle for the whole day (midnight sun).
+	ErrSunNeverSets = errors.New("sun never sets below the horizon on this date")
+)
+
+// Times holds the solar events of a single day. Events that do not occur on
+// that day (e.g. astronomical dusk during a high-latitude summer) are left as
+// the zero time.
+type Times struct {
+	AstronomicalDawn time.Time
+	NauticalDawn     time.Time
+	CivilDawn        time.Time
+	Sunrise          time.Time
+	SolarNoon        time.Time
+	Sunset           time.Time
+	CivilDusk        time.Time
+	NauticalDusk     time.Time
+	AstronomicalDusk time.Time
+}
+
+// Calculate returns all solar events for the calendar day of date at the given
+// location. Latitude is positive north, longitude positive east. Results are
+// returned in date's location.
+func Calculate(lat, lng float64, date time.Time) *Times {
+	return &Times{
+		AstronomicalDawn: riseOrZero(lat, lng, date, ZenithAstronomical),
+		NauticalDawn:     riseOrZero(lat, lng, date, ZenithNautical),
+		CivilDawn:        riseOrZero(lat, lng, date, ZenithCivil),
+		Sunrise:          riseOrZero(lat, lng, date, ZenithSunrise),
+		SolarNoon:        Noon(lng, date),
+		Sunset:           setOrZero(lat, lng, date, ZenithSunrise),
+		CivilDusk:        setOrZero(lat, lng, date, ZenithCivil),
+		NauticalDusk:     setOrZero(lat, lng, date, ZenithNautical),
+		AstronomicalDusk: setOrZero(lat, lng, date, ZenithAstronomical),
+	}
+}
+
+// Rise returns the time on date's calendar day when the sun's center climbs
+// through the given zenith angle.
+func Rise(lat, lng float64, date time.Time, zenith float64) (time.Time, error) {
+	return riseSet(true, lat, lng, date, zenith)
+}
+
+// Set returns the time on date's calendar day when the sun's center sinks
+// through the given zenith angle.
+func Set(lat, lng float64, date time.Time, zenith float64) (time.Time, error) {
+	return riseSet(false, lat, lng, date, zenith)
+}
+
+// Noon returns the time of solar noon (the sun's transit) on date's calendar day.
+func Noon(lng float64, date time.Time) time.Time {
+	jd := julianDay(date)
+
+	t := julianCentury(jd - lng/360.0)
+	minutes := 720 - 4*lng - equationOfTime(t)
+	// Refine using the equation of time at the first estimate
+	t = julianCentury(jd + minutes/1440.0)
+	minutes = 720 - 4*lng - equationOfTime(t)
+
+	return fromMinutesUTC(date, minutes)
+}
+
+func riseOrZero(lat, lng float64, date time.Time, zenith float64) time.Time {
+	t, _ := Rise(lat, lng, date, zenith)
+	return t
+}
+
+func setOrZero(lat, lng float64, date time.Time, zenith float64) time.Time {
+	t, _ := Set(lat, lng, date, zenith)
+	return t
+}
+
+func riseSet(rise bool, lat, lng float64, date time.Time, zenith float64) (time.Time, error) {
+	jd := julianDay(date)
+
+	minutes, err := riseSetMinutesUTC(rise, jd, lat, lng, zenith)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Second pass evaluates the sun's position at the estimated event time
+	minutes, err = riseSetMinutesUTC(rise, jd+minutes/1440.0, lat, lng, zenith)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return fromMinutesUTC(date, minutes), nil
+}
+
+// riseSetMinutesUTC returns the event time in minutes after 00:00 UTC of the
+// day starting at jd. The value may fall outside [0, 1440) for locations far
+// from Greenwich.
+func riseSetMinutesUTC(rise bool, jd, lat, lng, zenith float64) (float64, error) {
+	t := julianCentury(jd)
+	eqTime := equationOfTime(t)
+	decl := sunDeclination(t)
+
+	hourAngle, err := hourAngle(lat, decl, zenith)
+	if err != nil {
+		return 0, err
+	}
+	if !rise {
+		hourAngle = -hourAngle
+	}
+
+	delta := lng + radToDeg(hourAngle)
+	return 720 - 4*delta - eqTime, nil
+}
+
+// hourAngle returns the hour angle (radians) at which the sun reaches the
+// given zenith angle.
+func hourAngle(lat, decl, zenith float64) (float64, error) {
+	latRad := degToRad(lat)
+	declRad := degToRad(decl)
+
+	arg := math.Cos(degToRad(zenith))/(math.Cos(latRad)*math.Cos(declRad)) - math.Tan(latRad)*math.Tan(declRad)
+	if arg > 1 {
+		return 0, ErrSunNeverRises
+	}
+	if arg < -1 {
+		return 0, ErrSunNeverSets
+	}
+	return math.Acos(arg), nil
+}
+
+// julianDay returns the Julian day number at 00:00 UTC of date's calendar day.
+func julianDay(date time.Time) float64 {
+	year, month, day := date.Date()
+	y := float64(year)
+	m := float64(month)
+	if m <= 2 {
+		y--
+		m += 12
+	}
+	a := math.Floor(y / 100)
+	b := 2 - a + math.Floor(a/4)
+	return math.Floor(365.25*(y+4716)) + math.Floor(30.6001*(m+1)) + float64(day) + b - 1524.5
+}
+
+func julianCentury(jd float64) float64 {
+	return (jd - 2451545.0) / 36525.0
+}
+
+// fromMinutesUTC converts minutes after 00:00 UTC of date's calendar day into
+// a time in date's location.
+func fromMinutesUTC(date time.Time, minutes float64) time.Time {
+	year, month, day := date.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return midnight.Add(time.Duration(minutes * float64(time.Minute))).Round(time.Second).In(date.Location())
+}
+
+func geomMeanLongSun(t float64) float64 {
+	l0 := 280.46646 + t*(36000.76983+t*0.0003032)
+	l0 = math.Mod(l0, 360)
+	if l0 < 0 {
+		l0 += 360
+	}
+	return l0
+}
+
+func geomMeanAnomalySun(t float64) float64 {
+	return 357.52911 + t*(35999.05029-0.0001537*t)
+}
+
+func eccentricityEarthOrbit(t float64) float64 {
+	return 0.016708634 - t*(0.000042037+0.0000001267*t)
+}
+
+func sunEqOfCenter(t float64) float64 {
+	m := degToRad(geomMeanAnomalySun(t))
+	return math.Sin(m)*(1.914602-t*(0.004817+0.000014*t)) +
+		math.Sin(2*m)*(0.019993-0.000101*t) +
+		math.Sin(3*m)*0.000289
+}
+
+func sunTrueLong(t float64) float64 {
+	return geomMeanLongSun(t) + sunEqOfCenter(t)
+}
+
+func sunApparentLong(t float64) float64 {
+	omega := 125.04 - 1934.136*t
+	return sunTrueLong(t) - 0.00569 - 0.00478*math.Sin(degToRad(omega))
+}
+
+func meanObliquityOfEcliptic(t float64) float64 {
+	seconds := 21.448 - t*(46.8150+t*(0.00059-t*0.001813))
+	return 23 + (26+seconds/60)/60
+}
+
+func obliquityCorrection(t float64) float64 {
+	omega := 125.04 - 1934.136*t
+	return meanObliquityOfEcliptic(t) + 0.00256*math.Cos(degToRad(omega))
+}
+
+// sunDeclination returns the sun's declination in degrees.
+func sunDeclination(t float64) float64 {
+	e := degToRad(obliquityCorrection(t))
+	lambda := degToRad(sunApparentLong(t))
+	return radToDeg(math.Asin(math.Sin(e) * math.Sin(lambda)))
+}
+
+// equationOfTime returns the difference between apparent and mean solar time
+// in minutes.
+func equationOfTime(t float64) float64 {
+	epsilon := degToRad(obliquityCorrection(t))
+	l0 := degToRad(geomMeanLongSun(t))
+	e := eccentricityEarthOrbit(t)
+	m := degToRad(geomMeanAnomalySun(t))
+
+	y := math.Tan(epsilon / 2)
+	y *= y
+
+	eTime := y*math.Sin(2*l0) -
+		2*e*math.Sin(m) +
+		4*e*y*math.Sin(m)*math.Cos(2*l0) -
+		0.5*y*y*math.Sin(4*l0) -
+		1.25*e*e*math.Sin(2*m)
+
+	return radToDeg(eTime) * 4
+}
+
+func degToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
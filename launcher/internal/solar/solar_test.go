@@ -0,0 +1,119 @@
+package solar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// tolerance is how far a computed time may be from the published reference
+const tolerance = time.Minute
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestCalculateReferenceDates(t *testing.T) {
+	london := mustLoad(t, "Europe/London")
+	losAngeles := mustLoad(t, "America/Los_Angeles")
+	oslo := mustLoad(t, "Europe/Oslo")
+
+	type place struct {
+		lat, lng float64
+		loc      *time.Location
+	}
+	greenwich := place{51.5074, -0.1278, london}
+	sanBernardino := place{34.1083, -117.2898, losAngeles}
+	tromso := place{69.6492, 18.9553, oslo}
+
+	// Reference times are the NOAA Solar Calculator's, to the minute
+	tests := []struct {
+		name    string
+		place   place
+		date    string
+		sunrise string
+		noon    string
+		sunset  string
+	}{
+		{"london march equinox", greenwich, "2024-03-20", "06:02", "12:08", "18:14"},
+		{"london june solstice", greenwich, "2024-06-20", "04:43", "13:02", "21:21"},
+		{"london september equinox", greenwich, "2024-09-22", "06:47", "12:53", "18:58"},
+		{"london december solstice", greenwich, "2024-12-21", "08:04", "11:59", "15:54"},
+		{"san bernardino june solstice", sanBernardino, "2024-06-20", "05:38", "12:51", "20:04"},
+		{"san bernardino december solstice", sanBernardino, "2024-12-21", "06:51", "11:48", "16:44"},
+		{"tromso march equinox", tromso, "2024-03-20", "05:42", "11:51", "18:03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := time.ParseInLocation("2006-01-02", tt.date, tt.place.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := Calculate(tt.place.lat, tt.place.lng, date)
+			check := func(event string, got time.Time, want string) {
+				t.Helper()
+				w, err := time.ParseInLocation("2006-01-02 15:04", tt.date+" "+want, tt.place.loc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if d := got.Sub(w); d < -tolerance || d > tolerance {
+					t.Errorf("%s = %s, want %s (±%s)", event, got.Format("15:04:05"), want, tolerance)
+				}
+				if got.Location() != tt.place.loc {
+					t.Errorf("%s is in %s, want %s", event, got.Location(), tt.place.loc)
+				}
+			}
+			check("sunrise", got.Sunrise, tt.sunrise)
+			check("solar noon", got.SolarNoon, tt.noon)
+			check("sunset", got.Sunset, tt.sunset)
+		})
+	}
+}
+
+func TestCalculatePolar(t *testing.T) {
+	oslo := mustLoad(t, "Europe/Oslo")
+	const lat, lng = 69.6492, 18.9553 // Tromsø
+
+	tests := []struct {
+		name string
+		date string
+		// wantErr is what Rise returns for sunrise
+		wantErr error
+		// civilDawn reports whether civil dawn still occurs
+		civilDawn bool
+	}{
+		{"midnight sun", "2024-06-21", ErrSunNeverSets, false},
+		{"polar night", "2024-12-21", ErrSunNeverRises, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := time.ParseInLocation("2006-01-02", tt.date, oslo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := Calculate(lat, lng, date)
+			if !got.Sunrise.IsZero() || !got.Sunset.IsZero() {
+				t.Errorf("sunrise/sunset = %v/%v, want zero times", got.Sunrise, got.Sunset)
+			}
+			if got.SolarNoon.IsZero() {
+				t.Error("solar noon is zero, want a time")
+			}
+			if got.CivilDawn.IsZero() == tt.civilDawn {
+				t.Errorf("civil dawn = %v, want it to occur: %v", got.CivilDawn, tt.civilDawn)
+			}
+			if _, err := Rise(lat, lng, date, ZenithSunrise); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Rise error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := Set(lat, lng, date, ZenithSunrise); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Set error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Package state keeps the launcher's broadcast history in a JSON file.
+//
+// Every access goes through Update or View, which hold an exclusive lock on a
+// sidecar lock file, so concurrent cron/Task Scheduler invocations can't
+// interleave their read-modify-write cycles. Writes go to a temp file that is
+// renamed over the state file, so a crash never leaves it half-written.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"launcher/internal/atomicfile"
+	"os"
+	"time"
+)
+
+// Broadcast statuses
+const (
+	StatusScheduled = "scheduled"
+	StatusTesting   = "testing"
+	StatusLive      = "live"
+	StatusComplete  = "complete"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+	StatusSkipped   = "skipped"
+)
+
+// Weather gate decisions
+const (
+	DecisionGo    = "go"
+	DecisionDelay = "delay"
+	DecisionSkip  = "skip"
+)
+
+// Transition records when a broadcast reached a lifecycle status
+type Transition struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// WeatherCheck is a go/no-go decision made from the weather station before going live
+type WeatherCheck struct {
+	At       time.Time `json:"at"`
+	Decision string    `json:"decision"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+type Broadcast struct {
+	ID           string       `json:"id"`
+	Title        string       `json:"title"`
+	StreamID     string       `json:"stream_id,omitempty"`
+	PlannedStart time.Time    `json:"planned_start"`
+	PlannedEnd   time.Time    `json:"planned_end"`
+	Status       string       `json:"status"`
+	Error        string       `json:"error,omitempty"`
+	Transitions  []Transition `json:"transitions,omitempty"`
+	// WeatherChecks are the weather gate's decisions, oldest first
+	WeatherChecks []WeatherCheck `json:"weather_checks,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// TransitionTime returns when the broadcast reached status, or the zero time
+func (b *Broadcast) TransitionTime(status string) time.Time {
+	for _, t := range b.Transitions {
+		if t.Status == status {
+			return t.At
+		}
+	}
+	return time.Time{}
+}
+
+type State struct {
+	// Current is the most recently scheduled broadcast, used when no ID is given
+	Current    string       `json:"current,omitempty"`
+	Broadcasts []*Broadcast `json:"broadcasts"`
+}
+
+// Find returns the broadcast with the given ID, or nil
+func (s *State) Find(id string) *Broadcast {
+	for _, b := range s.Broadcasts {
+		if b.ID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+// Add records a newly scheduled broadcast and makes it current
+func (s *State) Add(b *Broadcast) {
+	s.insert(b)
+	s.Current = b.ID
+}
+
+// Track returns the broadcast with the given ID, adding it if it is unknown so
+// broadcasts created outside the launcher still get a history. Unlike Add it
+// leaves Current alone.
+func (s *State) Track(id string) *Broadcast {
+	if b := s.Find(id); b != nil {
+		return b
+	}
+	b := &Broadcast{ID: id}
+	s.insert(b)
+	return b
+}
+
+func (s *State) insert(b *Broadcast) {
+	now := time.Now()
+	if b.Status == "" {
+		b.Status = StatusScheduled
+	}
+	b.CreatedAt = now
+	b.UpdatedAt = now
+	s.Broadcasts = append(s.Broadcasts, b)
+}
+
+// Transition records that a broadcast reached status. Unknown IDs are tracked
+// without becoming current.
+func (s *State) Transition(id, status string) *Broadcast {
+	b := s.Track(id)
+	now := time.Now()
+	b.Status = status
+	b.Transitions = append(b.Transitions, Transition{Status: status, At: now})
+	b.UpdatedAt = now
+	return b
+}
+
+// Fail marks a broadcast as failed with the error that caused it
+func (s *State) Fail(id string, cause error) *Broadcast {
+	b := s.Transition(id, StatusFailed)
+	b.Error = cause.Error()
+	return b
+}
+
+// CheckWeather records a weather gate decision. A skip also ends the broadcast's lifecycle.
+func (s *State) CheckWeather(id, decision, reason string) *Broadcast {
+	b := s.Track(id)
+	if decision == DecisionSkip {
+		b = s.Transition(id, StatusSkipped)
+	}
+	b.WeatherChecks = append(b.WeatherChecks, WeatherCheck{At: time.Now(), Decision: decision, Reason: reason})
+	b.UpdatedAt = time.Now()
+	return b
+}
+
+type Store struct {
+	path string
+}
+
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Update runs fn on the current state under the lock and saves the result.
+// Nothing is written if fn returns an error.
+func (s *Store) Update(fn func(*State) error) error {
+	unlock, err := lock(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+	return s.write(st)
+}
+
+// View runs fn on the current state under the lock without saving
+func (s *Store) View(fn func(*State) error) error {
+	unlock, err := lock(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	return fn(st)
+}
+
+func (s *Store) read() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %v", err)
+	}
+
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %v", s.path, err)
+	}
+	return &st, nil
+}
+
+func (s *Store) write(st *State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnknownIDsDoNotBecomeCurrent(t *testing.T) {
+	var st State
+	st.Add(&Broadcast{ID: "scheduled"})
+
+	st.Transition("foreign", StatusLive)
+	st.Fail("other", errors.New("boom"))
+	st.CheckWeather("third", DecisionGo, "")
+
+	if st.Current != "scheduled" {
+		t.Errorf("Current = %q, want %q", st.Current, "scheduled")
+	}
+	for _, id := range []string{"foreign", "other", "third"} {
+		if st.Find(id) == nil {
+			t.Errorf("%s was not added to the history", id)
+		}
+	}
+	if b := st.Find("other"); b.Status != StatusFailed || b.Error != "boom" {
+		t.Errorf("other = %s %q, want failed %q", b.Status, b.Error, "boom")
+	}
+	if len(st.Broadcasts) != 4 {
+		t.Errorf("%d broadcasts, want 4", len(st.Broadcasts))
+	}
+}
+
+func TestTrackKnownID(t *testing.T) {
+	var st State
+	st.Add(&Broadcast{ID: "a", Title: "Sunrise"})
+	if b := st.Track("a"); b.Title != "Sunrise" || len(st.Broadcasts) != 1 {
+		t.Errorf("Track returned %+v with %d broadcasts, want the existing one", b, len(st.Broadcasts))
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "state.json"))
+	err := store.Update(func(st *State) error {
+		st.Add(&Broadcast{ID: "a"})
+		st.Transition("b", StatusLive)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed update writes nothing
+	errAbort := errors.New("abort")
+	if err := store.Update(func(st *State) error {
+		st.Current = ""
+		return errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Fatalf("Update = %v, want %v", err, errAbort)
+	}
+
+	err = store.View(func(st *State) error {
+		if st.Current != "a" {
+			t.Errorf("Current = %q, want a", st.Current)
+		}
+		if b := st.Find("b"); b == nil || b.Status != StatusLive || b.TransitionTime(StatusLive).IsZero() {
+			t.Errorf("b = %+v, want live with a transition time", b)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package thumbnail renders broadcast thumbnails: the title, date, sun times and location
+// over a dawn-to-dusk gradient, using the Go fonts so the output is the same on every OS.
+package thumbnail
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of a thumbnail, YouTube's recommended 1280x720
+const (
+	Width  = 1280
+	Height = 720
+)
+
+const (
+	margin          = 64
+	titleSize       = 80
+	smallTitleSize  = 60
+	maxTitleLines   = 3
+	detailSize      = 42
+	shadowOffset    = 3
+	backgroundTop   = 0x0b1d3a
+	backgroundBelow = 0xe07b39
+)
+
+// Card is what a thumbnail shows. Zero Sunrise/Sunset and an empty Location are left out.
+type Card struct {
+	Title    string
+	Date     time.Time
+	Sunrise  time.Time
+	Sunset   time.Time
+	Location string
+}
+
+// Render draws the card
+func Render(c Card) (*image.RGBA, error) {
+	regular, bold, err := loadFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	drawGradient(img, rgb(backgroundTop), rgb(backgroundBelow))
+
+	// The title runs down from the top, shrinking if it needs more than maxTitleLines
+	titleFace, err := newFace(bold, titleSize)
+	if err != nil {
+		return nil, err
+	}
+	lines := wrap(titleFace, c.Title, Width-2*margin)
+	if len(lines) > maxTitleLines {
+		if titleFace, err = newFace(bold, smallTitleSize); err != nil {
+			return nil, err
+		}
+		lines = wrap(titleFace, c.Title, Width-2*margin)
+	}
+	y := margin + titleFace.Metrics().Ascent.Ceil()
+	for _, line := range lines {
+		drawText(img, titleFace, line, margin, y)
+		y += titleFace.Metrics().Height.Ceil()
+	}
+
+	// The details run up from the bottom
+	var details []string
+	if !c.Date.IsZero() {
+		details = append(details, c.Date.Format("Monday, January 2, 2006"))
+	}
+	if !c.Sunrise.IsZero() && !c.Sunset.IsZero() {
+		details = append(details, fmt.Sprintf("Sunrise %s  ·  Sunset %s", c.Sunrise.Format("3:04 PM"), c.Sunset.Format("3:04 PM")))
+	}
+	if c.Location != "" {
+		details = append(details, c.Location)
+	}
+	detailFace, err := newFace(regular, detailSize)
+	if err != nil {
+		return nil, err
+	}
+	y = Height - margin
+	for i := len(details) - 1; i >= 0; i-- {
+		drawText(img, detailFace, details[i], margin, y)
+		y -= detailFace.Metrics().Height.Ceil()
+	}
+	return img, nil
+}
+
+// WritePNG renders the card as a PNG
+func WritePNG(w io.Writer, c Card) error {
+	img, err := Render(c)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+var fonts struct {
+	once          sync.Once
+	regular, bold *opentype.Font
+	err           error
+}
+
+func loadFonts() (regular, bold *opentype.Font, err error) {
+	fonts.once.Do(func() {
+		if fonts.regular, fonts.err = opentype.Parse(goregular.TTF); fonts.err != nil {
+			return
+		}
+		fonts.bold, fonts.err = opentype.Parse(gobold.TTF)
+	})
+	if fonts.err != nil {
+		return nil, nil, fmt.Errorf("error loading font: %v", fonts.err)
+	}
+	return fonts.regular, fonts.bold, nil
+}
+
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, fmt.Errorf("error loading font: %v", err)
+	}
+	return face, nil
+}
+
+// drawGradient fills img with a vertical gradient from top to bottom
+func drawGradient(img *image.RGBA, top, bottom color.RGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		t := float64(y-b.Min.Y) / float64(b.Dy()-1)
+		c := color.RGBA{
+			R: mix(top.R, bottom.R, t),
+			G: mix(top.G, bottom.G, t),
+			B: mix(top.B, bottom.B, t),
+			A: 0xff,
+		}
+		draw.Draw(img, image.Rect(b.Min.X, y, b.Max.X, y+1), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+}
+
+// drawText draws a line in white with a drop shadow, with its baseline at y
+func drawText(img draw.Image, face font.Face, text string, x, y int) {
+	d := &font.Drawer{Dst: img, Face: face}
+	d.Src = image.NewUniform(color.RGBA{A: 0x99})
+	d.Dot = fixed.P(x+shadowOffset, y+shadowOffset)
+	d.DrawString(text)
+	d.Src = image.White
+	d.Dot = fixed.P(x, y)
+	d.DrawString(text)
+}
+
+// wrap splits text into lines no wider than width, breaking between words. A word wider
+// than width gets a line of its own.
+func wrap(face font.Face, text string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+		if line != "" && font.MeasureString(face, next).Ceil() > width {
+			lines = append(lines, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func mix(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+}
+
+func rgb(hex uint32) color.RGBA {
+	return color.RGBA{R: uint8(hex >> 16), G: uint8(hex >> 8), B: uint8(hex), A: 0xff}
+}
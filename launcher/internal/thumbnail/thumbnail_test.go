@@ -0,0 +1,151 @@
+package thumbnail
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden PNGs in testdata")
+
+var eastern = time.FixedZone("EDT", -4*60*60)
+
+func clock(hour, min int) time.Time {
+	return time.Date(2026, 10, 16, hour, min, 0, 0, eastern)
+}
+
+const longTitle = "North Ridge Weather Cam - Live Wind, Gusts and Thermals for Pilots Flying Marshall Peak"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		golden string
+		card   Card
+	}{
+		{
+			golden: "card.png",
+			card: Card{
+				Title:    "North Ridge - Live",
+				Date:     clock(0, 0),
+				Sunrise:  clock(7, 31),
+				Sunset:   clock(18, 47),
+				Location: "Marshall, NC",
+			},
+		},
+		{
+			golden: "long_title.png",
+			card: Card{
+				Title:    longTitle,
+				Date:     clock(0, 0),
+				Sunrise:  clock(7, 31),
+				Sunset:   clock(18, 47),
+				Location: "Marshall, NC",
+			},
+		},
+		{
+			golden: "date_only.png",
+			card: Card{
+				Title: "North Ridge - Live",
+				Date:  clock(0, 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			img, err := Render(tt.card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				var buf bytes.Buffer
+				if err := png.Encode(&buf, img); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			compareGolden(t, img, path)
+		})
+	}
+}
+
+// The long title only covers the smaller size if it really is too long for the large one
+func TestLongTitleShrinks(t *testing.T) {
+	_, bold, err := loadFonts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		size     float64
+		tooLong  bool
+		sizeName string
+	}{
+		{titleSize, true, "titleSize"},
+		{smallTitleSize, false, "smallTitleSize"},
+	} {
+		face, err := newFace(bold, tt.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(wrap(face, longTitle, Width-2*margin)); (n > maxTitleLines) != tt.tooLong {
+			t.Errorf("long title wraps to %d lines at %s, maxTitleLines is %d", n, tt.sizeName, maxTitleLines)
+		}
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, Card{Title: "North Ridge - Live", Date: clock(0, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareGolden(t, img, filepath.Join("testdata", "date_only.png"))
+}
+
+// compareGolden fails unless img matches the golden PNG at path pixel for pixel.
+// Run the tests with -update to rewrite the goldens after an intended change.
+func compareGolden(t *testing.T, img image.Image, path string) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%v (run 'go test -update' to create it)", err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("error decoding %s: %v", path, err)
+	}
+	if img.Bounds() != want.Bounds() {
+		t.Fatalf("size %v, golden %s is %v", img.Bounds(), path, want.Bounds())
+	}
+
+	var diff int
+	var first image.Point
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r1, g1, b1, a1 := img.At(x, y).RGBA()
+			r2, g2, b2, a2 := want.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				if diff == 0 {
+					first = image.Pt(x, y)
+				}
+				diff++
+			}
+		}
+	}
+	if diff > 0 {
+		t.Errorf("%d pixels differ from %s, the first at %v (run 'go test -update' if the change is intended)", diff, path, first)
+	}
+}
@@ -0,0 +1,25 @@
+// Package wx reads wind data from a Crestline Soaring Arduino Weather Station
+// (https://github.com/crestlinesoaring/ArduinoWeatherStation).
+package wx
+
+import "math"
+
+var cardinalPoints = [16]string{
+	"N", "NNE", "NE", "ENE",
+	"E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW",
+	"W", "WNW", "NW", "NNW",
+}
+
+// Cardinal converts a direction in degrees to one of 16 compass points, e.g. 225 is "SW".
+// Each point covers 22.5 degrees centred on it, so N covers 348.75 to 11.25.
+func Cardinal(degrees float64) string {
+	if math.IsNaN(degrees) || math.IsInf(degrees, 0) {
+		return "N/A"
+	}
+	deg := math.Mod(degrees, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return cardinalPoints[int(math.Floor((deg+11.25)/22.5))%16]
+}
@@ -0,0 +1,94 @@
+package wx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Defaults used by the station at Marshall
+const (
+	DefaultBaseURL = "https://www.flymarshall.com/wx/betaTwo/wx"
+	DefaultSuffix  = ".dat"
+)
+
+// ErrNoData is returned when the station has no file, or no readings, for the requested day
+var ErrNoData = errors.New("no station data for this day")
+
+// Client fetches a station's daily files, named <BaseURL>YYYYMMDD<Suffix>
+type Client struct {
+	BaseURL string
+	Suffix  string
+	// Location is the station's time zone (default: local time)
+	Location   *time.Location
+	HTTPClient *http.Client
+}
+
+func (c *Client) location() *time.Location {
+	if c.Location != nil {
+		return c.Location
+	}
+	return time.Local
+}
+
+// URL returns the address of date's file
+func (c *Client) URL(date time.Time) string {
+	return c.BaseURL + date.In(c.location()).Format("20060102") + c.Suffix
+}
+
+// Fetch returns date's file as the station wrote it
+func (c *Client) Fetch(ctx context.Context, date time.Time) ([]byte, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	url := c.URL(date)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoData
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", url, err)
+	}
+	return data, nil
+}
+
+// Day fetches and parses date's file
+func (c *Client) Day(ctx context.Context, date time.Time) (*Day, error) {
+	data, err := c.Fetch(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(data), c.location())
+}
+
+// Latest returns the most recent record in today's file
+func (c *Client) Latest(ctx context.Context) (Record, error) {
+	day, err := c.Day(ctx, time.Now())
+	if err != nil {
+		return Record{}, err
+	}
+	rec, ok := day.Latest()
+	if !ok {
+		return Record{}, ErrNoData
+	}
+	return rec, nil
+}
@@ -0,0 +1,67 @@
+package wx
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limits are the conditions the readings must meet for a stream to go live. Zero disables a check.
+type Limits struct {
+	// MaxAge is how old the latest reading may be before the station counts as down
+	MaxAge time.Duration
+	// MaxWind is the highest acceptable sustained wind, in mph
+	MaxWind float64
+	// MaxGust is the highest acceptable gust, in mph
+	MaxGust float64
+	// Window is how long the wind or gusts must stay above their limit to fail, so a single
+	// spike doesn't. Zero checks the latest reading alone.
+	Window time.Duration
+}
+
+// Check returns why records, in time order, fail the limits at now, or "" if they meet them.
+// The wind or gusts fail only if every reading in the Window up to the latest is above the limit.
+func (l Limits) Check(records []Record, now time.Time) string {
+	if len(records) == 0 {
+		return "the weather station has no readings"
+	}
+	latest := records[len(records)-1]
+	if l.MaxAge > 0 {
+		if age := now.Sub(latest.Time); age > l.MaxAge {
+			return fmt.Sprintf("latest reading is %s old (limit %s)", age.Round(time.Minute), l.MaxAge)
+		}
+	}
+
+	window := records[len(records)-1:]
+	for i := len(records) - 2; i >= 0 && !records[i].Time.Before(latest.Time.Add(-l.Window)); i-- {
+		window = records[i:]
+	}
+	if l.MaxWind > 0 {
+		if lowest := lowest(window, func(r Record) float64 { return r.Wind }); lowest > l.MaxWind {
+			return describeOver("wind", lowest, l.MaxWind, window)
+		}
+	}
+	if l.MaxGust > 0 {
+		if lowest := lowest(window, func(r Record) float64 { return r.Gust }); lowest > l.MaxGust {
+			return describeOver("gust", lowest, l.MaxGust, window)
+		}
+	}
+	return ""
+}
+
+func lowest(records []Record, value func(Record) float64) float64 {
+	low := value(records[0])
+	for _, rec := range records[1:] {
+		if v := value(rec); v < low {
+			low = v
+		}
+	}
+	return low
+}
+
+func describeOver(what string, lowest, limit float64, window []Record) string {
+	if len(window) == 1 {
+		return fmt.Sprintf("%s %.1f mph is above %.1f mph", what, lowest, limit)
+	}
+	span := window[len(window)-1].Time.Sub(window[0].Time)
+	return fmt.Sprintf("%s has stayed above %.1f mph for %s (%d readings, lowest %.1f mph)", what, limit, span.Round(time.Minute), len(window), lowest)
+}
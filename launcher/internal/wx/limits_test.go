@@ -0,0 +1,151 @@
+package wx
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// minutely returns a reading a minute for each wind/gust pair, the last one at 12:00
+func minutely(pairs ...[2]float64) []Record {
+	var records []Record
+	last := at("12:00:00")
+	for i, p := range pairs {
+		records = append(records, Record{
+			Time: last.Add(time.Duration(i-len(pairs)+1) * time.Minute),
+			Wind: p[0],
+			Gust: p[1],
+		})
+	}
+	return records
+}
+
+func TestLimitsCheck(t *testing.T) {
+	limits := Limits{MaxAge: 15 * time.Minute, MaxWind: 20, MaxGust: 30, Window: 5 * time.Minute}
+	now := at("12:03:00")
+
+	tests := []struct {
+		name    string
+		limits  Limits
+		records []Record
+		now     time.Time
+		want    string
+	}{
+		{
+			name:    "calm",
+			limits:  limits,
+			records: minutely([2]float64{8, 12}, [2]float64{9, 14}, [2]float64{7, 11}),
+			now:     now,
+		},
+		{
+			name:    "no readings",
+			limits:  limits,
+			records: nil,
+			now:     now,
+			want:    "no readings",
+		},
+		{
+			name:    "stale",
+			limits:  limits,
+			records: minutely([2]float64{8, 12}),
+			now:     at("12:20:00"),
+			want:    "latest reading is 20m0s old (limit 15m0s)",
+		},
+		{
+			name:   "sustained wind",
+			limits: limits,
+			records: minutely(
+				[2]float64{8, 12}, // before the window
+				[2]float64{22, 26}, [2]float64{24, 28}, [2]float64{21, 27},
+				[2]float64{23, 29}, [2]float64{25, 29}, [2]float64{22, 28},
+			),
+			now:  now,
+			want: "wind has stayed above 20.0 mph for 5m0s (6 readings, lowest 21.0 mph)",
+		},
+		{
+			name:   "single spike",
+			limits: limits,
+			records: minutely(
+				[2]float64{8, 12}, [2]float64{9, 14}, [2]float64{10, 13},
+				[2]float64{9, 12}, [2]float64{8, 13}, [2]float64{26, 36},
+			),
+			now: now,
+		},
+		{
+			name:   "single lull",
+			limits: limits,
+			records: minutely(
+				[2]float64{22, 26}, [2]float64{24, 28}, [2]float64{12, 18},
+				[2]float64{23, 29}, [2]float64{25, 29}, [2]float64{22, 28},
+			),
+			now: now,
+		},
+		{
+			name:   "sustained gusts",
+			limits: limits,
+			records: minutely(
+				[2]float64{15, 31}, [2]float64{16, 33}, [2]float64{14, 32},
+				[2]float64{15, 35}, [2]float64{17, 34}, [2]float64{16, 32},
+			),
+			now:  now,
+			want: "gust has stayed above 30.0 mph for 5m0s (6 readings, lowest 31.0 mph)",
+		},
+		{
+			name:    "no window checks the latest reading",
+			limits:  Limits{MaxWind: 20},
+			records: minutely([2]float64{8, 12}, [2]float64{26, 36}),
+			now:     now,
+			want:    "wind 26.0 mph is above 20.0 mph",
+		},
+		{
+			name:    "window with one reading",
+			limits:  limits,
+			records: minutely([2]float64{26, 36}),
+			now:     now,
+			want:    "wind 26.0 mph is above 20.0 mph",
+		},
+		{
+			name:    "limits off",
+			limits:  Limits{Window: 5 * time.Minute},
+			records: minutely([2]float64{50, 70}, [2]float64{55, 75}),
+			now:     at("18:00:00"),
+		},
+		{
+			name:    "at the limit",
+			limits:  limits,
+			records: minutely([2]float64{20, 30}, [2]float64{20, 30}),
+			now:     now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.limits.Check(tt.records, tt.now)
+			if tt.want == "" && got != "" || tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("Check = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitsCheckFixture(t *testing.T) {
+	day := parseFixture(t, "wx20251127.dat")
+	limits := Limits{MaxAge: 15 * time.Minute, MaxGust: 19, Window: 15 * time.Minute}
+
+	// The lone 21 mph gust at 8:00 doesn't fail the morning, the gusty afternoon does
+	upTo := func(clock string) []Record {
+		var records []Record
+		for _, rec := range day.Records {
+			if !rec.Time.After(at(clock)) {
+				records = append(records, rec)
+			}
+		}
+		return records
+	}
+	if got := limits.Check(upTo("08:00:00"), at("08:01:00")); got != "" {
+		t.Errorf("Check at 8:00 = %q, want none", got)
+	}
+	if got := limits.Check(upTo("13:30:00"), at("13:31:00")); !strings.Contains(got, "gust has stayed above 19.0 mph") {
+		t.Errorf("Check at 13:30 = %q, want the gusts over the limit", got)
+	}
+}
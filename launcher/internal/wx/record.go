@@ -0,0 +1,147 @@
+package wx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is one line of a station file. Its columns are documented in the station's
+// Data String Composition:
+//
+//	time (HH:MM), date (M/D/YYYY), wind speed (mph), max gust over 5 min (mph), direction (degrees)
+//
+// Columns after those are kept as written in Extra.
+type Record struct {
+	// Time is the time and date columns together, in the station's time zone
+	Time time.Time `json:"time"`
+	// Wind is the wind speed in mph
+	Wind float64 `json:"wind_mph"`
+	// Gust is the highest gust over the last 5 minutes in mph
+	Gust float64 `json:"gust_mph"`
+	// Direction is where the wind is coming from, in degrees
+	Direction float64  `json:"direction_deg"`
+	Extra     []string `json:"extra,omitempty"`
+}
+
+// Columns of a station line, in order
+const (
+	columnTime = iota
+	columnDate
+	columnWind
+	columnGust
+	columnDirection
+	documentedColumns
+)
+
+// Cardinal returns the 16-point compass direction the wind is coming from
+func (r Record) Cardinal() string {
+	return Cardinal(r.Direction)
+}
+
+// LineError describes a line that could not be parsed
+type LineError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Day is a parsed station file
+type Day struct {
+	Records []Record
+	// Malformed lines are skipped and reported here
+	Malformed []*LineError
+}
+
+// Latest returns the most recent record
+func (d *Day) Latest() (Record, bool) {
+	if len(d.Records) == 0 {
+		return Record{}, false
+	}
+	return d.Records[len(d.Records)-1], true
+}
+
+// Parse reads a station file. Times are interpreted in loc, the station's time zone.
+// Blank lines are ignored and malformed lines are skipped, so one bad write by the
+// station doesn't lose the rest of the day.
+func Parse(r io.Reader, loc *time.Location) (*Day, error) {
+	day := &Day{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		rec, err := ParseRecord(line, loc)
+		if err != nil {
+			day.Malformed = append(day.Malformed, &LineError{Line: n, Text: line, Err: err})
+			continue
+		}
+		day.Records = append(day.Records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return day, nil
+}
+
+// ParseRecord parses a single line of a station file. A nil loc means local time.
+func ParseRecord(line string, loc *time.Location) (Record, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	fields := strings.Split(strings.TrimSpace(line), ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	if len(fields) < documentedColumns {
+		return Record{}, fmt.Errorf("expected at least %d fields, got %d", documentedColumns, len(fields))
+	}
+
+	var rec Record
+	var err error
+	if rec.Time, err = parseTimestamp(fields[columnDate], fields[columnTime], loc); err != nil {
+		return Record{}, err
+	}
+	if rec.Wind, err = parseNumber("wind speed", fields[columnWind]); err != nil {
+		return Record{}, err
+	}
+	if rec.Gust, err = parseNumber("gust", fields[columnGust]); err != nil {
+		return Record{}, err
+	}
+	if rec.Direction, err = parseNumber("direction", fields[columnDirection]); err != nil {
+		return Record{}, err
+	}
+	if len(fields) > documentedColumns {
+		rec.Extra = fields[documentedColumns:]
+	}
+	return rec, nil
+}
+
+func parseTimestamp(date, clock string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"1/2/2006 15:04", "1/2/2006 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, date+" "+clock, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date/time %q %q", date, clock)
+}
+
+func parseNumber(name, s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("negative %s %q", name, s)
+	}
+	return v, nil
+}
@@ -0,0 +1,177 @@
+package wx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// station is the fixtures' time zone, fixed so the tests don't need tzdata
+var station = time.FixedZone("PST", -8*60*60)
+
+func at(clock string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", "2025-11-27 "+clock, station)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func parseFixture(t *testing.T, name string) *Day {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	day, err := Parse(f, station)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+func TestParseRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Record
+		wantErr string
+	}{
+		{
+			name: "documented columns",
+			line: "13:13,11/27/2025,13.0,18,225",
+			want: Record{Time: at("13:13:00"), Wind: 13, Gust: 18, Direction: 225},
+		},
+		{
+			name: "extra columns",
+			line: "13:13,11/27/2025,13.0,18,225,61.2,12.6",
+			want: Record{Time: at("13:13:00"), Wind: 13, Gust: 18, Direction: 225, Extra: []string{"61.2", "12.6"}},
+		},
+		{
+			name: "padding and leading zeros",
+			line: "  6:05 , 11/27/2025 , 05.5 , 08 , 090  ",
+			want: Record{Time: at("06:05:00"), Wind: 5.5, Gust: 8, Direction: 90},
+		},
+		{
+			name: "seconds",
+			line: "6:05:30,11/27/2025,0,0,0",
+			want: Record{Time: at("06:05:30")},
+		},
+		{name: "blank", line: "", wantErr: "expected at least 5 fields, got 1"},
+		{name: "short", line: "13:13,11/27/2025,13.0,18", wantErr: "expected at least 5 fields, got 4"},
+		{name: "bad wind", line: "13:13,11/27/2025,calm,18,225", wantErr: `invalid wind speed "calm"`},
+		{name: "bad gust", line: "13:13,11/27/2025,13,,225", wantErr: `invalid gust ""`},
+		{name: "bad direction", line: "13:13,11/27/2025,13,18,SW", wantErr: `invalid direction "SW"`},
+		{name: "not a number", line: "13:13,11/27/2025,NaN,18,225", wantErr: `invalid wind speed "NaN"`},
+		{name: "infinite", line: "13:13,11/27/2025,13,+Inf,225", wantErr: `invalid gust "+Inf"`},
+		{name: "negative", line: "13:13,11/27/2025,-1,18,225", wantErr: `negative wind speed "-1"`},
+		{name: "bad date", line: "13:13,2025-11-27,13,18,225", wantErr: "invalid date/time"},
+		{name: "bad time", line: "25:13,11/27/2025,13,18,225", wantErr: "invalid date/time"},
+		{name: "swapped time and date", line: "11/27/2025,13:13,13,18,225", wantErr: "invalid date/time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecord(tt.line, station)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Time.Equal(tt.want.Time) || got.Wind != tt.want.Wind || got.Gust != tt.want.Gust ||
+				got.Direction != tt.want.Direction || strings.Join(got.Extra, ",") != strings.Join(tt.want.Extra, ",") {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecordLocalTime(t *testing.T) {
+	rec, err := ParseRecord("13:13,11/27/2025,13,18,225", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Time.Location() != time.Local {
+		t.Errorf("location = %s, want Local", rec.Time.Location())
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	day := parseFixture(t, "wx20251127.dat")
+	if len(day.Malformed) != 0 {
+		t.Errorf("malformed lines in a clean day: %v", day.Malformed)
+	}
+	// Every 5 minutes from 6:30 to 17:30
+	if len(day.Records) != 133 {
+		t.Fatalf("%d records, want 133", len(day.Records))
+	}
+	first, last := day.Records[0], day.Records[len(day.Records)-1]
+	if !first.Time.Equal(at("06:30:00")) || !last.Time.Equal(at("17:30:00")) {
+		t.Errorf("records run %s to %s, want 06:30 to 17:30", first.Time, last.Time)
+	}
+	latest, ok := day.Latest()
+	if !ok || !latest.Time.Equal(last.Time) {
+		t.Errorf("Latest = %v, %v", latest.Time, ok)
+	}
+	for _, rec := range day.Records {
+		if len(rec.Extra) != 2 {
+			t.Fatalf("%s: extra columns %q, want 2", rec.Time.Format("15:04"), rec.Extra)
+		}
+	}
+}
+
+func TestParseMessyDay(t *testing.T) {
+	day := parseFixture(t, "messy.dat")
+
+	var times []string
+	for _, rec := range day.Records {
+		times = append(times, rec.Time.Format("15:04:05"))
+	}
+	if got, want := strings.Join(times, " "), "06:30:00 06:35:00 07:05:30 07:15:00"; got != want {
+		t.Errorf("records at %s, want %s", got, want)
+	}
+	if rec := day.Records[1]; rec.Wind != 2.5 || rec.Gust != 5 || rec.Direction != 195 {
+		t.Errorf("padded line parsed as %+v", rec)
+	}
+
+	// Blank lines are skipped silently; the rest are reported with their line numbers
+	var lines []int
+	for _, e := range day.Malformed {
+		lines = append(lines, e.Line)
+		if e.Text == "" || e.Err == nil {
+			t.Errorf("line %d reported without its text or error", e.Line)
+		}
+	}
+	want := []int{5, 6, 7, 8, 9, 11, 12, 14}
+	if len(lines) != len(want) {
+		t.Fatalf("malformed lines %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("malformed lines %v, want %v", lines, want)
+		}
+	}
+	if got := day.Malformed[len(day.Malformed)-1].Error(); got != "line 14: expected at least 5 fields, got 4" {
+		t.Errorf("error for the truncated last line = %q", got)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	day, err := Parse(strings.NewReader("\n\r\n  \n"), station)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(day.Records) != 0 || len(day.Malformed) != 0 {
+		t.Errorf("day = %+v, want nothing", day)
+	}
+	if _, ok := day.Latest(); ok {
+		t.Error("Latest reported a record for an empty day")
+	}
+}
@@ -0,0 +1,133 @@
+package wx
+
+import (
+	"fmt"
+	"time"
+)
+
+// Conditions a segment of the day can be in
+const (
+	ConditionCalm     = "Calm"
+	ConditionThermals = "Thermals building"
+	ConditionGusty    = "Gusty"
+)
+
+// Thresholds decide how readings are split into segments
+type Thresholds struct {
+	// Calm is the sustained wind in mph below which a reading is calm
+	Calm float64
+	// Gusty is the gust in mph at or above which a reading is gusty
+	Gusty float64
+	// MinLength is the shortest segment; shorter ones are merged into the one before
+	MinLength time.Duration
+}
+
+// Condition classifies a reading
+func (t Thresholds) Condition(rec Record) string {
+	switch {
+	case rec.Gust >= t.Gusty:
+		return ConditionGusty
+	case rec.Wind < t.Calm:
+		return ConditionCalm
+	default:
+		return ConditionThermals
+	}
+}
+
+// Segment is a stretch of the day with the same conditions
+type Segment struct {
+	Start     time.Time
+	End       time.Time
+	Condition string
+	// Direction is the segment's prevailing direction, or "" if it was calm throughout
+	Direction string
+	records   []Record
+}
+
+// Title describes the segment, e.g. "Calm" or "Gusty W 20+"
+func (s Segment) Title(t Thresholds) string {
+	if s.Condition != ConditionGusty {
+		return s.Condition
+	}
+	if s.Direction == "" {
+		return fmt.Sprintf("%s %.0f+", s.Condition, t.Gusty)
+	}
+	return fmt.Sprintf("%s %s %.0f+", s.Condition, s.Direction, t.Gusty)
+}
+
+// Segments splits the records from start to end into consecutive segments. The first
+// segment starts at start and the last ends at end. It returns nil if there are no records.
+func Segments(records []Record, start, end time.Time, t Thresholds) []Segment {
+	var segs []Segment
+	for _, rec := range records {
+		if rec.Time.Before(start) || rec.Time.After(end) {
+			continue
+		}
+		cond := t.Condition(rec)
+		if n := len(segs); n > 0 && segs[n-1].Condition == cond {
+			segs[n-1].records = append(segs[n-1].records, rec)
+			continue
+		}
+		segs = append(segs, Segment{Start: rec.Time, Condition: cond, records: []Record{rec}})
+	}
+	if len(segs) == 0 {
+		return nil
+	}
+	segs[0].Start = start
+
+	// Merge the shortest segment into its neighbour until all are long enough, so a
+	// single gust or lull doesn't make its own segment
+	for len(segs) > 1 {
+		shortest := -1
+		for i := range segs {
+			if l := segmentLength(segs, i, end); l < t.MinLength && (shortest < 0 || l < segmentLength(segs, shortest, end)) {
+				shortest = i
+			}
+		}
+		if shortest < 0 {
+			break
+		}
+		segs = mergeSegment(segs, shortest)
+	}
+
+	for i := range segs {
+		segs[i].End = end
+		if i+1 < len(segs) {
+			segs[i].End = segs[i+1].Start
+		}
+		if s := Summarize(segs[i].records, segs[i].Start, segs[i].End); s != nil {
+			segs[i].Direction = s.Prevailing
+		}
+	}
+	return segs
+}
+
+func segmentLength(segs []Segment, i int, end time.Time) time.Duration {
+	if i+1 < len(segs) {
+		return segs[i+1].Start.Sub(segs[i].Start)
+	}
+	return end.Sub(segs[i].Start)
+}
+
+// mergeSegment merges segment i into the one before it (the one after, for the first),
+// then joins the neighbours if that left two with the same condition next to each other
+func mergeSegment(segs []Segment, i int) []Segment {
+	into := i - 1
+	if i == 0 {
+		into = 1
+		segs[1].Start = segs[0].Start
+		segs[1].records = append(segs[0].records, segs[1].records...)
+	} else {
+		segs[into].records = append(segs[into].records, segs[i].records...)
+	}
+	segs = append(segs[:i], segs[i+1:]...)
+	if i == 0 {
+		into = 0
+	}
+
+	if into+1 < len(segs) && segs[into].Condition == segs[into+1].Condition {
+		segs[into].records = append(segs[into].records, segs[into+1].records...)
+		segs = append(segs[:into+1], segs[into+2:]...)
+	}
+	return segs
+}
@@ -0,0 +1,121 @@
+package wx
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSegments(t *testing.T) {
+	day := parseFixture(t, "wx20251127.dat")
+	thresholds := Thresholds{Calm: 5, Gusty: 20, MinLength: 15 * time.Minute}
+
+	tests := []struct {
+		name       string
+		start, end string
+		thresholds Thresholds
+		want       []string
+	}{
+		{
+			// The lone gust at 8:00 is merged into the calm morning
+			name:       "whole day",
+			start:      "06:30:00",
+			end:        "17:30:00",
+			thresholds: thresholds,
+			want: []string{
+				"06:30-10:00 Calm",
+				"10:00-13:00 Thermals building",
+				"13:00-15:30 Gusty W 20+",
+				"15:30-16:45 Thermals building",
+				"16:45-17:30 Calm",
+			},
+		},
+		{
+			name:       "broadcast started late and ended early",
+			start:      "11:02:00",
+			end:        "14:00:00",
+			thresholds: thresholds,
+			want: []string{
+				"11:02-13:00 Thermals building",
+				"13:00-14:00 Gusty W 20+",
+			},
+		},
+		{
+			// The calm evening is too short and goes to the thermals before it
+			name:       "long minimum",
+			start:      "06:30:00",
+			end:        "17:30:00",
+			thresholds: Thresholds{Calm: 5, Gusty: 20, MinLength: 2 * time.Hour},
+			want: []string{
+				"06:30-10:00 Calm",
+				"10:00-13:00 Thermals building",
+				"13:00-15:30 Gusty W 20+",
+				"15:30-17:30 Thermals building",
+			},
+		},
+		{
+			name:       "higher gust threshold",
+			start:      "06:30:00",
+			end:        "17:30:00",
+			thresholds: Thresholds{Calm: 5, Gusty: 30, MinLength: 15 * time.Minute},
+			want: []string{
+				"06:30-10:00 Calm",
+				"10:00-16:45 Thermals building",
+				"16:45-17:30 Calm",
+			},
+		},
+		{
+			name:       "no minimum keeps the lone gust",
+			start:      "07:00:00",
+			end:        "09:00:00",
+			thresholds: Thresholds{Calm: 5, Gusty: 20},
+			want: []string{
+				"07:00-08:00 Calm",
+				"08:00-08:05 Gusty S 20+",
+				"08:05-09:00 Calm",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segs := Segments(day.Records, at(tt.start), at(tt.end), tt.thresholds)
+			var got []string
+			for _, seg := range segs {
+				got = append(got, seg.Start.Format("15:04")+"-"+seg.End.Format("15:04")+" "+seg.Title(tt.thresholds))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("segments:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSegmentsWithoutRecords(t *testing.T) {
+	day := parseFixture(t, "wx20251127.dat")
+	thresholds := Thresholds{Calm: 5, Gusty: 20, MinLength: 15 * time.Minute}
+	if segs := Segments(day.Records, at("18:00:00"), at("20:00:00"), thresholds); segs != nil {
+		t.Errorf("segments after the last reading = %+v, want none", segs)
+	}
+	if segs := Segments(nil, at("06:30:00"), at("17:30:00"), thresholds); segs != nil {
+		t.Errorf("segments without records = %+v, want none", segs)
+	}
+}
+
+func TestSegmentTitle(t *testing.T) {
+	thresholds := Thresholds{Gusty: 20}
+	tests := []struct {
+		seg  Segment
+		want string
+	}{
+		{Segment{Condition: ConditionCalm, Direction: "S"}, "Calm"},
+		{Segment{Condition: ConditionThermals, Direction: "SW"}, "Thermals building"},
+		{Segment{Condition: ConditionGusty, Direction: "WSW"}, "Gusty WSW 20+"},
+		{Segment{Condition: ConditionGusty}, "Gusty 20+"},
+	}
+	for _, tt := range tests {
+		if got := tt.seg.Title(thresholds); got != tt.want {
+			t.Errorf("Title(%+v) = %q, want %q", tt.seg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package wx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Summary describes the wind over a period
+type Summary struct {
+	// First and Last are the times of the first and last readings, the station's active window
+	First    time.Time
+	Last     time.Time
+	Readings int
+
+	MaxGust   float64
+	MaxGustAt time.Time
+	AvgWind   float64
+	// Prevailing is the most frequent 16-point direction, ignoring calm readings,
+	// or "" if the wind was calm throughout
+	Prevailing string
+}
+
+// Summarize summarizes the records from start to end, inclusive. It returns nil if there
+// are none.
+func Summarize(records []Record, start, end time.Time) *Summary {
+	var s *Summary
+	var total float64
+	counts := make(map[string]int)
+	for _, rec := range records {
+		if rec.Time.Before(start) || rec.Time.After(end) {
+			continue
+		}
+		if s == nil {
+			s = &Summary{First: rec.Time, MaxGust: rec.Gust, MaxGustAt: rec.Time}
+		}
+		s.Last = rec.Time
+		s.Readings++
+		total += rec.Wind
+		if rec.Gust > s.MaxGust {
+			s.MaxGust = rec.Gust
+			s.MaxGustAt = rec.Time
+		}
+		if rec.Wind > 0 {
+			counts[rec.Cardinal()]++
+		}
+	}
+	if s == nil {
+		return nil
+	}
+
+	s.AvgWind = total / float64(s.Readings)
+	best := 0
+	for _, point := range cardinalPoints {
+		if counts[point] > best {
+			best = counts[point]
+			s.Prevailing = point
+		}
+	}
+	return s
+}
+
+// SummaryHeading is the first line of Text
+const SummaryHeading = "Weather summary"
+
+// Text formats the summary as a block for a video description
+func (s *Summary) Text() string {
+	prevailing := s.Prevailing
+	if prevailing == "" {
+		prevailing = "calm"
+	}
+	lines := []string{
+		fmt.Sprintf("%s (%s-%s, %d readings)", SummaryHeading, s.First.Format("15:04"), s.Last.Format("15:04"), s.Readings),
+		fmt.Sprintf("Max gust: %.0f mph at %s", s.MaxGust, s.MaxGustAt.Format("15:04")),
+		fmt.Sprintf("Average wind: %.1f mph", s.AvgWind),
+		fmt.Sprintf("Prevailing direction: %s", prevailing),
+	}
+	return strings.Join(lines, "\n")
+}
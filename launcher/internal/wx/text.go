@@ -0,0 +1,64 @@
+package wx
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Text is what weather_data.lua shows in its wind and date/time text sources. The
+// formatting is kept identical so OBS text sources can switch over without changes.
+type Text struct {
+	Wind     string `json:"wind"`
+	DateTime string `json:"datetime"`
+}
+
+// LastFields returns the fields of the last non-empty line of a station file, split the
+// way weather_data.lua does: empty fields are dropped and nothing is trimmed
+func LastFields(data string) []string {
+	var last string
+	for _, line := range strings.FieldsFunc(data, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		last = line
+	}
+	return strings.FieldsFunc(last, func(r rune) bool { return r == ',' })
+}
+
+// FormatText formats a station line's fields at now. Outside hours the wind is blanked
+// and the date/time is marked offline.
+func FormatText(fields []string, now time.Time, hours Window) Text {
+	text := Text{DateTime: now.Format("2006/01/02 15:04")}
+	if !hours.Contains(now) {
+		text.Wind = "-- mph, -- mph, -- "
+		text.DateTime += " (offline)"
+		return text
+	}
+
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return "N/A"
+	}
+	text.Wind = stripLeadingZero(field(2)) + " mph, " + stripLeadingZero(field(3)) + " mph, " + cardinalText(field(4))
+	return text
+}
+
+// ErrorText is shown in both text sources when the station file can't be fetched
+func ErrorText(url string) Text {
+	msg := "Error: Could not fetch data from " + url
+	return Text{Wind: msg, DateTime: msg}
+}
+
+// stripLeadingZero removes a single leading zero, like string.gsub(s, "^0", "") in Lua
+func stripLeadingZero(s string) string {
+	return strings.TrimPrefix(s, "0")
+}
+
+// cardinalText converts a direction field, returning N/A for anything that isn't a number
+func cardinalText(s string) string {
+	deg, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return "N/A"
+	}
+	return Cardinal(deg)
+}
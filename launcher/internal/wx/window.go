@@ -0,0 +1,48 @@
+package wx
+
+import (
+	"fmt"
+	"time"
+)
+
+// Station hours used by weather_data.lua: readings outside them are shown as offline
+const (
+	DefaultBegin = "06:30"
+	DefaultEnd   = "17:30"
+)
+
+// Window is the time of day the station reports, in minutes since midnight
+type Window struct {
+	Begin int
+	End   int
+}
+
+// ParseWindow parses begin and end times of day written as HH:MM
+func ParseWindow(begin, end string) (Window, error) {
+	b, err := parseClock(begin)
+	if err != nil {
+		return Window{}, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return Window{}, err
+	}
+	if e < b {
+		return Window{}, fmt.Errorf("station hours end (%s) before they begin (%s)", end, begin)
+	}
+	return Window{Begin: b, End: e}, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q (expected HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t's time of day is within the window, ends included
+func (w Window) Contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	return minutes >= w.Begin && minutes <= w.End
+}
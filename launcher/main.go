@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"launcher/internal/obsws"
 	"launcher/internal/release"
+	"launcher/internal/state"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,7 +16,6 @@ import (
 	"time"
 )
 
-const broadcastIDFile = "broadcast_id.txt"
 const VERSION = "0.0.4"
 
 func printUsage() {
@@ -24,8 +27,17 @@ func printUsage() {
 	fmt.Println("  sunrise  Get sunrise time for a location")
 	fmt.Println("  sunset   Get sunset time for a location")
 	fmt.Println("  stream   Stream management commands")
+	fmt.Println("  auth     Sign in to YouTube and manage the saved token")
+	fmt.Println("  profile  Manage channel profiles")
+	fmt.Println("  config   Show the effective settings from config.yaml and the environment")
+	fmt.Println("  weather  Read the weather station")
+	fmt.Println("  overlay  Serve a wind overlay for an OBS browser source")
+	fmt.Println("  daemon   Run the daily stream schedule in the foreground")
 	fmt.Println("  update   Update the CLI to the latest release")
 	fmt.Println()
+	fmt.Println("Global options (before the command):")
+	fmt.Println("  --profile NAME  Use a channel profile's credentials and settings (default: $LAUNCHER_PROFILE or default)")
+	fmt.Println()
 	fmt.Println("Run 'launcher <command> --help' for more information on a command.")
 }
 
@@ -35,9 +47,14 @@ func printStreamUsage() {
 	fmt.Println("Usage: launcher stream <command> [options]")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  schedule  Create YouTube broadcast and schedule start/end tasks")
-	fmt.Println("  start     Start OBS and transition broadcast to live")
-	fmt.Println("  end       End the current broadcast")
+	fmt.Println("  schedule    Create YouTube broadcast and schedule start/end tasks")
+	fmt.Println("  start       Start OBS and transition broadcast to live")
+	fmt.Println("  end         End the current broadcast")
+	fmt.Println("  cancel      Delete the upcoming broadcast and its start/end tasks")
+	fmt.Println("  reschedule  Move the upcoming broadcast to a new time")
+	fmt.Println("  status      Show YouTube's view of a broadcast and its ingest health")
+	fmt.Println("  list        List recorded broadcasts")
+	fmt.Println("  show        Show the history of a broadcast")
 	fmt.Println()
 	fmt.Println("Run 'launcher stream <command> --help' for more information.")
 }
@@ -60,21 +77,48 @@ func main() {
 		os.Exit(1)
 	}
 
-	switch os.Args[1] {
+	args, profileName, err := extractProfileFlag(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(args) < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+	activeProfile, err = loadProfile(profileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading profile: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
 	case "sunrise":
-		cmdSunrise(os.Args[2:])
+		cmdSunrise(args[1:])
 	case "sunset":
-		cmdSunset(os.Args[2:])
+		cmdSunset(args[1:])
 	case "stream":
-		cmdStream(os.Args[2:])
+		cmdStream(args[1:])
+	case "auth":
+		cmdAuth(args[1:])
+	case "profile":
+		cmdProfile(args[1:])
+	case "config":
+		cmdConfig(args[1:])
+	case "weather":
+		cmdWeather(args[1:])
+	case "overlay":
+		cmdOverlay(args[1:])
+	case "daemon":
+		cmdDaemon(args[1:])
 	case "update":
-		cmdUpdate(os.Args[2:])
+		cmdUpdate(args[1:])
 	case "-help", "--help", "help":
 		printUsage()
 	case "-version", "--version", "version":
 		fmt.Printf("OBS Stream Launcher version %s\n", VERSION)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", args[0])
 		printUsage()
 		os.Exit(1)
 	}
@@ -94,6 +138,16 @@ func cmdStream(args []string) {
 		cmdStreamStart(args[1:])
 	case "end":
 		cmdStreamEnd(args[1:])
+	case "cancel":
+		cmdStreamCancel(args[1:])
+	case "reschedule":
+		cmdStreamReschedule(args[1:])
+	case "status":
+		cmdStreamStatus(args[1:])
+	case "list":
+		cmdStreamList(args[1:])
+	case "show":
+		cmdStreamShow(args[1:])
 	case "-help", "--help", "help":
 		printStreamUsage()
 	default:
@@ -105,42 +159,38 @@ func cmdStream(args []string) {
 
 // cmdSunrise handles the sunrise subcommand
 func cmdSunrise(args []string) {
-	fs := flag.NewFlagSet("sunrise", flag.ExitOnError)
-	city := fs.String("city", "", "City for lookup (e.g., 'San Bernardino, CA'). If not specified, uses IP geolocation")
-	offset := fs.Int("offset", 0, "Minutes offset from sunrise")
-	format := fs.String("format", "human", "Output format: 'human', 'datetime' (ISO format), or 'time' (HH:MM)")
-	fs.Usage = func() { printFlagUsage(fs, "launcher sunrise") }
-	fs.Parse(args)
-
-	sunTimes, locationName := getSunTimesForLocation(*city)
-	resultTime := sunTimes.Sunrise.Add(time.Duration(*offset) * time.Minute)
-
-	switch *format {
-	case "datetime":
-		fmt.Println(resultTime.Format("2006-01-02T15:04:05"))
-	case "time":
-		fmt.Println(resultTime.Format("15:04"))
-	default:
-		fmt.Printf("Location: %s\n", locationName)
-		fmt.Printf("Sunrise:  %s\n", sunTimes.Sunrise.Format("15:04:05"))
-		if *offset != 0 {
-			fmt.Printf("Offset:   %+d minutes\n", *offset)
-			fmt.Printf("Result:   %s\n", resultTime.Format("15:04:05"))
-		}
-	}
+	cmdSunEvent("sunrise", sunEventSunrise, args)
 }
 
 // cmdSunset handles the sunset subcommand
 func cmdSunset(args []string) {
-	fs := flag.NewFlagSet("sunset", flag.ExitOnError)
+	cmdSunEvent("sunset", sunEventSunset, args)
+}
+
+// cmdSunEvent prints the time of a sun event. sunrise and sunset only differ by their default event.
+func cmdSunEvent(command, defaultEvent string, args []string) {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
 	city := fs.String("city", "", "City for lookup (e.g., 'San Bernardino, CA'). If not specified, uses IP geolocation")
-	offset := fs.Int("offset", 0, "Minutes offset from sunset")
+	event := fs.String("event", defaultEvent, "Sun event: "+strings.Join(sunEvents, ", "))
+	offset := fs.Int("offset", 0, "Minutes offset from the event")
 	format := fs.String("format", "human", "Output format: 'human', 'datetime' (ISO format), or 'time' (HH:MM)")
-	fs.Usage = func() { printFlagUsage(fs, "launcher sunset") }
-	fs.Parse(args)
+	sunSource := fs.String("sun-source", sunSourceNOAA, "Sun times source: 'noaa' (computed offline) or 'api' (api.sunrise-sunset.org)")
+	fs.Usage = func() { printFlagUsage(fs, "launcher "+command) }
+	parseFlags(fs, args)
 
-	sunTimes, locationName := getSunTimesForLocation(*city)
-	resultTime := sunTimes.Sunset.Add(time.Duration(*offset) * time.Minute)
+	sunEvent, ok := parseSunEvent(*event)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: Unknown event '%s'. Use one of: %s\n", *event, strings.Join(sunEvents, ", "))
+		os.Exit(1)
+	}
+
+	sunTimes, locationName := getSunTimesForLocation(*city, *sunSource)
+	eventTime, err := sunTimes.Event(sunEvent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	resultTime := eventTime.Add(time.Duration(*offset) * time.Minute)
 
 	switch *format {
 	case "datetime":
@@ -149,7 +199,7 @@ func cmdSunset(args []string) {
 		fmt.Println(resultTime.Format("15:04"))
 	default:
 		fmt.Printf("Location: %s\n", locationName)
-		fmt.Printf("Sunset:   %s\n", sunTimes.Sunset.Format("15:04:05"))
+		fmt.Printf("%-9s %s\n", sunEventLabel(sunEvent)+":", eventTime.Format("15:04:05"))
 		if *offset != 0 {
 			fmt.Printf("Offset:   %+d minutes\n", *offset)
 			fmt.Printf("Result:   %s\n", resultTime.Format("15:04:05"))
@@ -157,27 +207,14 @@ func cmdSunset(args []string) {
 	}
 }
 
-func getSunTimesForLocation(city string) (*SunTimes, string) {
-	var lat, lng float64
-	var locationName string
-	var err error
-
-	if city != "" {
-		lat, lng, err = getLocationFromCity(city)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting location for city: %v\n", err)
-			os.Exit(1)
-		}
-		locationName = city
-	} else {
-		lat, lng, locationName, err = getLocationFromIP()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting location from IP: %v\n", err)
-			os.Exit(1)
-		}
+func getSunTimesForLocation(city, sunSource string) (*SunTimes, string) {
+	lat, lng, locationName, err := getLocation(city)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting location: %v\n", err)
+		os.Exit(1)
 	}
 
-	sunTimes, err := getSunTimes(lat, lng, time.Now())
+	sunTimes, err := getSunTimes(lat, lng, time.Now(), sunSource)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting sun times: %v\n", err)
 		os.Exit(1)
@@ -203,17 +240,24 @@ func cmdUpdate(_ []string) {
 func cmdStreamSchedule(args []string) {
 	fs := flag.NewFlagSet("stream schedule", flag.ExitOnError)
 
-	title := fs.String("title", "", "Stream title (default: 'Marshall WX (MM/DD/YYYY)')")
-	description := fs.String("description", "", "Stream description")
+	title := fs.String("title", "", "Stream title, a Go template (default: the profile's title template, 'Marshall WX (MM/DD/YYYY)' unless changed)")
+	description := fs.String("description", "", "Stream description, a Go template with the same data as --title")
 	privacy := fs.String("privacy", "public", "Privacy status: public, unlisted, or private")
+	thumbnailPath := fs.String("thumbnail", "", "Image (JPEG or PNG, up to 2MB) to upload as the thumbnail instead of rendering one, or 'none'")
+	videoFlag := videoFlags(fs)
 
 	city := fs.String("city", "", "City for sunrise/sunset lookup")
-	startTimeFlag := fs.String("time", "SUNRISE", "Start time: 'SUNRISE', 'SUNSET', or specific time 'YYYY-MM-DDTHH:MM:SS'")
-	startOffset := fs.Int("start-offset", -30, "Minutes offset from sunrise/sunset for start")
-	endOffset := fs.Int("end-offset", 30, "Minutes offset from sunset for end")
+	startTimeFlag := fs.String("time", "SUNRISE", "Start time: a sun event ("+strings.Join(sunEvents, ", ")+") or specific time 'YYYY-MM-DDTHH:MM:SS'")
+	endTimeFlag := fs.String("end-time", "SUNSET", "End time: a sun event or specific time 'YYYY-MM-DDTHH:MM:SS'")
+	startOffset := fs.Int("start-offset", defaultStartOffset, "Minutes offset from the start sun event")
+	endOffset := fs.Int("end-offset", defaultEndOffset, "Minutes offset from the end sun event")
+	sunSource := fs.String("sun-source", sunSourceNOAA, "Sun times source: 'noaa' (computed offline) or 'api' (api.sunrise-sunset.org)")
+
+	recur := fs.String("recur", "", "Repeat the schedule: 'daily' (next occurrence is scheduled after each 'stream end') or 'none' to stop repeating")
+	days := fs.String("days", "", "Days a recurring stream runs on, e.g. 'mon,wed,sat' or 'monday,wednesday' (default: every day)")
 
 	fs.Usage = func() { printFlagUsage(fs, "launcher stream schedule") }
-	fs.Parse(args)
+	parseFlags(fs, args)
 
 	fmt.Println("=== Stream Scheduler ===")
 	fmt.Println()
@@ -223,157 +267,330 @@ func cmdStreamSchedule(args []string) {
 		fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
 		os.Exit(1)
 	}
-	baseDir := filepath.Dir(execPath)
+	baseDir := activeProfile.Dir
+
+	opts := scheduleOptions{
+		Title:       *title,
+		Description: *description,
+		Privacy:     *privacy,
+		City:        *city,
+		StartTime:   *startTimeFlag,
+		EndTime:     *endTimeFlag,
+		StartOffset: *startOffset,
+		EndOffset:   *endOffset,
+		SunSource:   *sunSource,
+		Thumbnail:   *thumbnailPath,
+	}
+	opts.videoOptions = videoFlag()
+
+	var recurrence *Recurrence
+	switch strings.ToLower(*recur) {
+	case "":
+		if *days != "" {
+			recurrence, err = newRecurrence(opts, *days)
+		}
+	case "daily":
+		recurrence, err = newRecurrence(opts, *days)
+	case "none":
+		if err := clearRecurrence(baseDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error clearing recurrence: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Recurring schedule cleared")
+	default:
+		err = fmt.Errorf("unknown --recur value '%s' (expected 'daily' or 'none')", *recur)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Determine start time
-	var startTime time.Time
-	var endTime time.Time
-	timeUpper := strings.ToUpper(*startTimeFlag)
+	date := time.Now()
+	if recurrence != nil {
+		date, err = recurrence.firstDate(time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	if timeUpper == "SUNRISE" || timeUpper == "SUNSET" {
-		sunTimes, locationName := getSunTimesForLocation(*city)
-		fmt.Printf("Location: %s\n", locationName)
-		fmt.Printf("Sunrise:  %s\n", sunTimes.Sunrise.Format("15:04:05"))
-		fmt.Printf("Sunset:   %s\n", sunTimes.Sunset.Format("15:04:05"))
-
-		if timeUpper == "SUNRISE" {
-			startTime = sunTimes.Sunrise.Add(time.Duration(*startOffset) * time.Minute)
-			fmt.Printf("Stream start (sunrise %+d min): %s\n", *startOffset, startTime.Format("15:04:05"))
-		} else {
-			startTime = sunTimes.Sunset.Add(time.Duration(*startOffset) * time.Minute)
-			fmt.Printf("Stream start (sunset %+d min): %s\n", *startOffset, startTime.Format("15:04:05"))
+	if err := scheduleStreamOn(baseDir, execPath, opts, date); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if recurrence != nil {
+		if err := saveRecurrence(baseDir, recurrence); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving recurrence: %v\n", err)
+			os.Exit(1)
 		}
+		fmt.Printf("Repeats on: %s (next occurrence is scheduled after each 'stream end')\n", recurrence.describeDays())
+	}
 
-		endTime = sunTimes.Sunset.Add(time.Duration(*endOffset) * time.Minute)
-		fmt.Printf("Stream end (sunset %+d min): %s\n", *endOffset, endTime.Format("15:04:05"))
-	} else {
-		var err error
-		startTime, err = time.ParseInLocation("2006-01-02T15:04:05", *startTimeFlag, time.Local)
+	fmt.Println()
+	fmt.Println("=== Schedule Complete ===")
+	fmt.Println("The stream will automatically start and end at the scheduled times.")
+}
+
+// scheduleOptions are the settings of 'stream schedule'. Recurring schedules persist them
+// so 'stream end' can schedule the next occurrence.
+type scheduleOptions struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Privacy     string `json:"privacy"`
+	City        string `json:"city,omitempty"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+	SunSource   string `json:"sun_source,omitempty"`
+	Thumbnail   string `json:"thumbnail,omitempty"`
+	videoOptions
+}
+
+// streamPlan is the resolved start and end of a stream on a given day
+type streamPlan struct {
+	Start    time.Time
+	End      time.Time
+	Location string
+	SunTimes *SunTimes
+}
+
+// planStream resolves the start and end anchors for date's calendar day.
+// Sun times are only looked up if one of the anchors is a sun event or the text is templated.
+func planStream(opts scheduleOptions, date time.Time) (*streamPlan, error) {
+	plan := &streamPlan{}
+
+	_, startIsEvent := parseSunEvent(opts.StartTime)
+	_, endIsEvent := parseSunEvent(opts.EndTime)
+	usesTemplates := isTemplate(opts.Title) || isTemplate(opts.Description) || isTemplate(opts.Playlist) || isTemplate(activeProfile.titleTemplate())
+	if startIsEvent || endIsEvent || usesTemplates {
+		lat, lng, locationName, err := getLocation(opts.City)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Invalid time format. Use 'SUNRISE', 'SUNSET', or 'YYYY-MM-DDTHH:MM:SS'\n")
-			os.Exit(1)
+			return nil, fmt.Errorf("error getting location: %v", err)
 		}
-		fmt.Printf("Stream start: %s\n", startTime.Format("2006-01-02 15:04:05"))
+		sunTimes, err := getSunTimes(lat, lng, date, opts.SunSource)
+		if err != nil {
+			return nil, fmt.Errorf("error getting sun times: %v", err)
+		}
+		plan.Location = locationName
+		plan.SunTimes = sunTimes
+	}
 
-		// Still use sunset for end time
-		sunTimes, locationName := getSunTimesForLocation(*city)
-		fmt.Printf("Location: %s\n", locationName)
-		endTime = sunTimes.Sunset.Add(time.Duration(*endOffset) * time.Minute)
-		fmt.Printf("Stream end (sunset %+d min): %s\n", *endOffset, endTime.Format("15:04:05"))
+	var err error
+	plan.Start, err = resolveScheduleTime(opts.StartTime, opts.StartOffset, plan.SunTimes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time: %v", err)
+	}
+	plan.End, err = resolveScheduleTime(opts.EndTime, opts.EndOffset, plan.SunTimes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time: %v", err)
 	}
+
+	if !plan.End.After(plan.Start) {
+		return nil, fmt.Errorf("stream end (%s) must be after stream start (%s)", plan.End.Format("15:04:05"), plan.Start.Format("15:04:05"))
+	}
+	return plan, nil
+}
+
+// scheduleStreamOn creates the broadcast for date's calendar day and registers the start/end tasks
+func scheduleStreamOn(baseDir, execPath string, opts scheduleOptions, date time.Time) error {
+	plan, err := planStream(opts, date)
+	if err != nil {
+		return err
+	}
+
+	if plan.SunTimes != nil {
+		fmt.Printf("Location: %s\n", plan.Location)
+		fmt.Printf("Sunrise:  %s\n", plan.SunTimes.Sunrise.Format("15:04:05"))
+		fmt.Printf("Sunset:   %s\n", plan.SunTimes.Sunset.Format("15:04:05"))
+	}
+	fmt.Printf("Stream start%s: %s\n", describeScheduleTime(opts.StartTime, opts.StartOffset), plan.Start.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Stream end%s: %s\n", describeScheduleTime(opts.EndTime, opts.EndOffset), plan.End.Format("2006-01-02 15:04:05"))
 	fmt.Println()
 
-	today := time.Now()
-	streamTitle := *title
-	if streamTitle == "" {
-		streamTitle = fmt.Sprintf("Marshall WX (%s)", today.Format("01/02/2006"))
+	text := newBroadcastText(plan)
+	streamTitle, description, err := renderBroadcastText(opts.Title, opts.Description, text)
+	if err != nil {
+		return err
+	}
+	playlist, err := renderText("playlist", opts.Playlist, text)
+	if err != nil {
+		return err
 	}
 	fmt.Printf("Title: %s\n", streamTitle)
+	if playlist != "" {
+		fmt.Printf("Playlist: %s\n", playlist)
+	}
 	fmt.Println()
 
-	scheduler, err := NewStreamScheduler(baseDir)
+	scheduler, err := NewStreamScheduler(activeProfile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error initializing YouTube scheduler: %w", err)
 	}
+	scheduler.OnTransition(func(broadcastID, status string) { recordTransition(baseDir, broadcastID, status) })
 
-	broadcast, _, err := scheduler.ScheduleStream(streamTitle, *description, startTime, *privacy)
+	broadcast, stream, err := scheduler.ScheduleStream(streamTitle, description, plan.Start, opts.Privacy)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error scheduling stream: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error scheduling stream: %w", err)
 	}
 
-	bidFile := filepath.Join(baseDir, broadcastIDFile)
-	if err := os.WriteFile(bidFile, []byte(broadcast.Id), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Could not save broadcast ID to file: %v\n", err)
-	} else {
-		fmt.Printf("Broadcast ID saved to: %s\n", bidFile)
+	recordScheduledBroadcast(baseDir, &state.Broadcast{
+		ID:           broadcast.Id,
+		Title:        streamTitle,
+		StreamID:     stream.Id,
+		PlannedStart: plan.Start,
+		PlannedEnd:   plan.End,
+	})
+	setThumbnail(scheduler, broadcast.Id, opts.Thumbnail, streamTitle, plan)
+	setVideoOptions(scheduler, broadcast.Id, opts.videoOptions, playlist, opts.Privacy)
+
+	return registerStreamTasks(execPath, broadcast.Id, plan.Start, plan.End)
+}
+
+const (
+	startTaskName = "StartYouTubeStream"
+	endTaskName   = "EndYouTubeStream"
+)
+
+// registerStreamTasks creates (or replaces) the OS tasks that run 'stream start' and 'stream end'
+func registerStreamTasks(execPath, broadcastID string, start, end time.Time) error {
+	obsPath := activeProfile.settingValue("obs_path")
+	if obsPath == "" {
+		obsPath = getOBSPath()
+	}
+	workingDir := filepath.Dir(obsPath)
+	startCmd := fmt.Sprintf(`%s stream start -id "%s"`, activeProfile.commandPrefix(execPath), broadcastID)
+	if err := createScheduledTask(activeProfile.taskName(startTaskName), startCmd, workingDir, start); err != nil {
+		return fmt.Errorf("error creating start task: %v", err)
 	}
+	fmt.Printf("Scheduled start task for: %s\n", start.Format("2006-01-02 15:04"))
 
-	workingDir := filepath.Dir(getOBSPath())
-	startCmd := fmt.Sprintf(`"%s" stream start -id "%s"`, execPath, broadcast.Id)
-	if err := createScheduledTask("StartYouTubeStream", startCmd, workingDir, startTime); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating start task: %v\n", err)
-		os.Exit(1)
+	endCmd := fmt.Sprintf(`%s stream end -id "%s"`, activeProfile.commandPrefix(execPath), broadcastID)
+	if err := createScheduledTask(activeProfile.taskName(endTaskName), endCmd, workingDir, end); err != nil {
+		return fmt.Errorf("error creating end task: %v", err)
 	}
-	fmt.Printf("Scheduled start task for: %s\n", startTime.Format("15:04"))
+	fmt.Printf("Scheduled end task for: %s\n", end.Format("2006-01-02 15:04"))
 
-	endCmd := fmt.Sprintf(`"%s" stream end -id "%s"`, execPath, broadcast.Id)
-	if err := createScheduledTask("EndYouTubeStream", endCmd, workingDir, endTime); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating end task: %v\n", err)
-		os.Exit(1)
+	return nil
+}
+
+// resolveScheduleTime turns a --time/--end-time value into a concrete time.
+// Sun event names are looked up in sunTimes and shifted by offset minutes;
+// anything else must be a local 'YYYY-MM-DDTHH:MM:SS' timestamp.
+func resolveScheduleTime(value string, offset int, sunTimes *SunTimes) (time.Time, error) {
+	if event, ok := parseSunEvent(value); ok {
+		if sunTimes == nil {
+			return time.Time{}, fmt.Errorf("no sun times to resolve %s", event)
+		}
+		eventTime, err := sunTimes.Event(event)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return eventTime.Add(time.Duration(offset) * time.Minute), nil
 	}
-	fmt.Printf("Scheduled end task for: %s\n", endTime.Format("15:04"))
 
-	fmt.Println()
-	fmt.Println("=== Schedule Complete ===")
-	fmt.Println("The stream will automatically start and end at the scheduled times.")
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is not a sun event (%s) or a 'YYYY-MM-DDTHH:MM:SS' time", value, strings.Join(sunEvents, ", "))
+	}
+	return t, nil
+}
+
+// describeScheduleTime returns e.g. " (civil-dawn -30 min)" for sun event anchors
+func describeScheduleTime(value string, offset int) string {
+	if event, ok := parseSunEvent(value); ok {
+		return fmt.Sprintf(" (%s %+d min)", event, offset)
+	}
+	return ""
 }
 
 func cmdStreamStart(args []string) {
 	fs := flag.NewFlagSet("stream start", flag.ExitOnError)
 
-	broadcastID := fs.String("id", "", "Broadcast ID to start (default: read from broadcast_id.txt)")
+	broadcastID := fs.String("id", "", "Broadcast ID to start (default: the most recently scheduled broadcast)")
 	obsPath := fs.String("obs-path", "", "Custom path to OBS executable")
 	skipOBS := fs.Bool("skip-obs", false, "Skip starting OBS")
+	obsAddress := fs.String("obs-address", obsws.DefaultAddress, "obs-websocket server address (host:port)")
+	obsPassword := fs.String("obs-password", "", "obs-websocket password (default: $"+obsPasswordEnv+")")
+	obsTimeout := fs.Duration("obs-timeout", 2*time.Minute, "How long to wait for OBS to start streaming")
+	obsAssumeStreaming := fs.Bool("obs-assume-streaming", false, "Go live anyway if obs-websocket can't be reached after launching OBS")
+	liveTimeout := fs.Duration("live-timeout", DefaultGoLiveOptions().Timeout, "How long to wait for YouTube to see a healthy stream before going live")
+	pollInterval := fs.Duration("poll-interval", DefaultGoLiveOptions().PollInterval, "Initial delay between YouTube status checks (backs off up to 30s)")
+	gateFlag := weatherGateFlags(fs)
 
 	fs.Usage = func() { printFlagUsage(fs, "launcher stream start") }
-	fs.Parse(args)
+	parseFlags(fs, args)
+
+	gate, err := gateFlag()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("=== Starting Stream ===")
 	fmt.Println()
 
-	execPath, err := os.Executable()
+	baseDir := activeProfile.Dir
+
+	bid, err := resolveBroadcastID(baseDir, *broadcastID)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	baseDir := filepath.Dir(execPath)
 
-	bid := *broadcastID
-	if bid == "" {
-		bidFile := filepath.Join(baseDir, broadcastIDFile)
-		data, err := os.ReadFile(bidFile)
+	fmt.Printf("Broadcast ID: %s\n", bid)
+
+	// A skip clears the current broadcast, so whether the tasks are this one's is decided first
+	current, _ := resolveBroadcastID(baseDir, "")
+	skipReason, err := gate.wait(context.Background(), baseDir, bid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking the weather: %v\n", err)
+		os.Exit(1)
+	}
+	if skipReason != "" {
+		execPath, err := os.Executable()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: No broadcast ID provided and could not read %s: %v\n", bidFile, err)
+			fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
 			os.Exit(1)
 		}
-		bid = strings.TrimSpace(string(data))
-	}
-
-	if bid == "" {
-		fmt.Fprintf(os.Stderr, "Error: Broadcast ID is empty\n")
-		os.Exit(1)
+		if err := skipStream(baseDir, execPath, bid, skipReason, bid == current); err != nil {
+			fmt.Fprintf(os.Stderr, "Error skipping stream: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	fmt.Printf("Broadcast ID: %s\n", bid)
-
 	if !*skipOBS {
 		obsExe := *obsPath
 		if obsExe == "" {
 			obsExe = getOBSPath()
 		}
+		password := *obsPassword
+		if password == "" {
+			password = os.Getenv(obsPasswordEnv)
+		}
 
-		fmt.Printf("Starting OBS in directory: %s\n", obsExe)
-
-		obsCmd := exec.Command(obsExe, "--startstreaming")
-		obsCmd.Dir = filepath.Dir(obsExe)
-		if err := obsCmd.Start(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error starting OBS: %v\n", err)
-		} else {
-			fmt.Println("OBS started with streaming enabled")
-			// This sleep time here makes sure that OBS has enough time to initialize before transitioning the stream to live.
-			time.Sleep(30 * time.Second)
+		opts := obsOptions{path: obsExe, address: *obsAddress, password: password, timeout: *obsTimeout, assumeStreaming: *obsAssumeStreaming}
+		if err := startOBSStream(opts); err != nil {
+			recordFailure(baseDir, bid, err)
+			fmt.Fprintf(os.Stderr, "Error starting OBS stream: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
-	scheduler, err := NewStreamScheduler(baseDir)
+	scheduler, err := NewStreamScheduler(activeProfile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
 		os.Exit(1)
 	}
+	scheduler.OnTransition(func(broadcastID, status string) { recordTransition(baseDir, broadcastID, status) })
 
-	if err := scheduler.GoLive(bid); err != nil {
+	goLiveOpts := DefaultGoLiveOptions()
+	goLiveOpts.Timeout = *liveTimeout
+	goLiveOpts.PollInterval = *pollInterval
+	if err := scheduler.GoLive(bid, goLiveOpts); err != nil {
+		recordFailure(baseDir, bid, err)
 		fmt.Fprintf(os.Stderr, "Error transitioning to live: %v\n", err)
 		os.Exit(1)
 	}
@@ -384,9 +601,16 @@ func cmdStreamStart(args []string) {
 
 func cmdStreamEnd(args []string) {
 	fs := flag.NewFlagSet("stream end", flag.ExitOnError)
-	broadcastID := fs.String("id", "", "Broadcast ID to end (default: read from broadcast_id.txt)")
+	broadcastID := fs.String("id", "", "Broadcast ID to end (default: the most recently scheduled broadcast)")
+	vodFlag := vodFlags(fs)
 	fs.Usage = func() { printFlagUsage(fs, "launcher stream end") }
-	fs.Parse(args)
+	parseFlags(fs, args)
+
+	vodOpts, err := vodFlag()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("=== Ending Stream ===")
 	fmt.Println()
@@ -396,36 +620,291 @@ func cmdStreamEnd(args []string) {
 		fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
 		os.Exit(1)
 	}
-	baseDir := filepath.Dir(execPath)
+	baseDir := activeProfile.Dir
 
-	bid := *broadcastID
-	if bid == "" {
-		bidFile := filepath.Join(baseDir, broadcastIDFile)
-		data, err := os.ReadFile(bidFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: No broadcast ID provided and could not read %s: %v\n", bidFile, err)
-			os.Exit(1)
+	bid, err := resolveBroadcastID(baseDir, *broadcastID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Broadcast ID: %s\n", bid)
+
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
+		os.Exit(1)
+	}
+	scheduler.OnTransition(func(broadcastID, status string) { recordTransition(baseDir, broadcastID, status) })
+
+	endErr := scheduler.EndStream(bid)
+	if endErr == nil {
+		finishVOD(scheduler, baseDir, bid, vodOpts)
+	}
+
+	// A failed end (e.g. the broadcast was already complete) must not stop a recurring
+	// schedule, so the next occurrence is scheduled either way
+	nextErr := scheduleNextOccurrence(baseDir, execPath, time.Now())
+	if endErr != nil {
+		fmt.Fprintf(os.Stderr, "Error ending stream: %v\n", endErr)
+	}
+	if nextErr != nil {
+		fmt.Fprintf(os.Stderr, "Error scheduling next occurrence: %v\n", nextErr)
+	}
+	if endErr != nil || nextErr != nil {
+		os.Exit(1)
+	}
+}
+
+func cmdStreamCancel(args []string) {
+	fs := flag.NewFlagSet("stream cancel", flag.ExitOnError)
+	broadcastID := fs.String("id", "", "Broadcast ID to cancel (default: the most recently scheduled broadcast)")
+	fs.Usage = func() { printFlagUsage(fs, "launcher stream cancel") }
+	parseFlags(fs, args)
+
+	fmt.Println("=== Cancelling Stream ===")
+	fmt.Println()
+
+	execPath, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
+		os.Exit(1)
+	}
+	baseDir := activeProfile.Dir
+
+	bid, err := resolveBroadcastID(baseDir, *broadcastID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Broadcast ID: %s\n", bid)
+
+	// The start/end tasks belong to the current broadcast, so they stay when cancelling another
+	current, _ := resolveBroadcastID(baseDir, "")
+
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := scheduler.DeleteBroadcast(bid); err != nil {
+		fmt.Fprintf(os.Stderr, "Error cancelling broadcast: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Broadcast deleted")
+
+	if bid == current {
+		for _, task := range []string{activeProfile.taskName(startTaskName), activeProfile.taskName(endTaskName)} {
+			if err := deleteScheduledTask(task); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing %s task: %v\n", task, err)
+				os.Exit(1)
+			}
 		}
-		bid = strings.TrimSpace(string(data))
+		fmt.Println("Start/end tasks removed")
+	} else {
+		fmt.Println("Start/end tasks left in place: they belong to the current broadcast")
 	}
 
-	if bid == "" {
-		fmt.Fprintf(os.Stderr, "Error: Broadcast ID is empty\n")
+	plannedStart := recordCancellation(baseDir, bid)
+	if bid != current {
+		return
+	}
+
+	// A recurring schedule continues with the occurrence after the cancelled one
+	if plannedStart.IsZero() {
+		plannedStart = time.Now()
+	}
+	if err := scheduleNextOccurrence(baseDir, execPath, plannedStart); err != nil {
+		fmt.Fprintf(os.Stderr, "Error scheduling next occurrence: %v\n", err)
 		os.Exit(1)
 	}
+}
 
+func cmdStreamReschedule(args []string) {
+	fs := flag.NewFlagSet("stream reschedule", flag.ExitOnError)
+	broadcastID := fs.String("id", "", "Broadcast ID to reschedule (default: the most recently scheduled broadcast)")
+	city := fs.String("city", "", "City for sunrise/sunset lookup")
+	startTimeFlag := fs.String("time", "", "New start time: a sun event ("+strings.Join(sunEvents, ", ")+") or specific time 'YYYY-MM-DDTHH:MM:SS'")
+	endTimeFlag := fs.String("end-time", "", "New end time: a sun event or specific time 'YYYY-MM-DDTHH:MM:SS' (default: the broadcast's planned end)")
+	startOffset := fs.Int("start-offset", defaultStartOffset, "Minutes offset from the start sun event")
+	endOffset := fs.Int("end-offset", defaultEndOffset, "Minutes offset from the end sun event")
+	sunSource := fs.String("sun-source", sunSourceNOAA, "Sun times source: 'noaa' (computed offline) or 'api' (api.sunrise-sunset.org)")
+	fs.Usage = func() { printFlagUsage(fs, "launcher stream reschedule") }
+	parseFlags(fs, args)
+	endTimeGiven := flagGiven(args, "end-time")
+
+	if *startTimeFlag == "" {
+		fmt.Fprintln(os.Stderr, "Error: --time is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	fmt.Println("=== Rescheduling Stream ===")
+	fmt.Println()
+
+	execPath, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
+		os.Exit(1)
+	}
+	baseDir := activeProfile.Dir
+
+	bid, err := resolveBroadcastID(baseDir, *broadcastID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	fmt.Printf("Broadcast ID: %s\n", bid)
 
-	scheduler, err := NewStreamScheduler(baseDir)
+	// Sun events are resolved for the day the broadcast was planned on
+	date := plannedStartOf(baseDir, bid)
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	// Only the start moves unless a new end is given; the end_time setting is for new broadcasts
+	endTime := *endTimeFlag
+	if !endTimeGiven {
+		if plannedEnd := plannedEndOf(baseDir, bid); !plannedEnd.IsZero() {
+			endTime = plannedEnd.In(time.Local).Format("2006-01-02T15:04:05")
+		} else if endTime == "" {
+			endTime = "SUNSET"
+		}
+	}
+
+	opts := scheduleOptions{
+		City:        *city,
+		StartTime:   *startTimeFlag,
+		EndTime:     endTime,
+		StartOffset: *startOffset,
+		EndOffset:   *endOffset,
+		SunSource:   *sunSource,
+	}
+	plan, err := planStream(opts, date)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Stream start%s: %s\n", describeScheduleTime(opts.StartTime, opts.StartOffset), plan.Start.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Stream end%s: %s\n", describeScheduleTime(opts.EndTime, opts.EndOffset), plan.End.Format("2006-01-02 15:04:05"))
+	fmt.Println()
+
+	scheduler, err := NewStreamScheduler(activeProfile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := scheduler.EndStream(bid); err != nil {
-		fmt.Fprintf(os.Stderr, "Error ending stream: %v\n", err)
+	if err := scheduler.RescheduleBroadcast(bid, plan.Start, plan.End); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rescheduling broadcast: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Println("Broadcast start and end times updated")
+
+	moved, err := rescheduleTasks(baseDir, bid, plan.Start, plan.End, func(start, end time.Time) error {
+		return registerStreamTasks(execPath, bid, start, end)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !moved {
+		fmt.Printf("Start/end tasks left in place: they belong to the current broadcast (run 'stream start -id %s' to start this one)\n", bid)
+	}
+
+	fmt.Println()
+	fmt.Println("=== Reschedule Complete ===")
+}
+
+// rescheduleTasks records a broadcast's new times and moves the start/end tasks to them with
+// register. The tasks belong to the current broadcast, so they stay where they are when another
+// broadcast is rescheduled. It reports whether the tasks were moved.
+func rescheduleTasks(baseDir, broadcastID string, start, end time.Time, register func(start, end time.Time) error) (bool, error) {
+	current, _ := resolveBroadcastID(baseDir, "")
+	moved := broadcastID == current
+	if moved {
+		if err := register(start, end); err != nil {
+			return false, err
+		}
+	}
+	recordReschedule(baseDir, broadcastID, start, end)
+	return moved, nil
+}
+
+func cmdStreamStatus(args []string) {
+	fs := flag.NewFlagSet("stream status", flag.ExitOnError)
+	broadcastID := fs.String("id", "", "Broadcast ID to check (default: the most recently scheduled broadcast)")
+	format := fs.String("format", "human", "Output format: 'human' or 'json'")
+	fs.Usage = func() { printFlagUsage(fs, "launcher stream status") }
+	parseFlags(fs, args)
+
+	baseDir := activeProfile.Dir
+
+	bid, err := resolveBroadcastID(baseDir, *broadcastID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	scheduler, err := NewStreamScheduler(activeProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing YouTube scheduler: %v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := scheduler.GetBroadcastReport(bid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting broadcast status: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding status: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Println()
+		fmt.Printf("Broadcast:       %s\n", report.BroadcastID)
+		fmt.Printf("Title:           %s\n", report.Title)
+		fmt.Printf("Lifecycle:       %s\n", report.LifeCycleStatus)
+		fmt.Printf("Privacy:         %s\n", report.PrivacyStatus)
+		fmt.Printf("Scheduled start: %s\n", formatAPITime(report.ScheduledStartTime))
+		fmt.Printf("Scheduled end:   %s\n", formatAPITime(report.ScheduledEndTime))
+		fmt.Printf("Actual start:    %s\n", formatAPITime(report.ActualStartTime))
+		fmt.Printf("Actual end:      %s\n", formatAPITime(report.ActualEndTime))
+		fmt.Println()
+
+		if report.Stream == nil {
+			fmt.Println("Stream:          (not bound)")
+			return
+		}
+		fmt.Printf("Stream:          %s (%s)\n", report.Stream.StreamID, report.Stream.Title)
+		fmt.Printf("Stream status:   %s\n", report.Stream.StreamStatus)
+		fmt.Printf("Health:          %s\n", report.Stream.HealthStatus)
+		if len(report.Stream.ConfigurationIssues) > 0 {
+			fmt.Println("Configuration issues:")
+			for _, issue := range report.Stream.ConfigurationIssues {
+				fmt.Printf("  [%s] %s: %s\n", issue.Severity, issue.Type, issue.Description)
+			}
+		}
+	}
+}
+
+// formatAPITime formats an RFC 3339 timestamp from the YouTube API in local time
+func formatAPITime(value string) string {
+	if value == "" {
+		return "-"
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
 }
 
 func createScheduledTask(taskName, command, workingDir string, runTime time.Time) error {
@@ -466,6 +945,11 @@ func createUnixTask(taskName, command string, runTime time.Time) error {
 	month := int(runTime.Month())
 	cronEntry := fmt.Sprintf("%d %d %d %d * %s # TASK:%s", minute, hour, day, month, command, taskName)
 
+	return replaceCrontabTask(taskName, cronEntry)
+}
+
+// replaceCrontabTask removes the crontab lines tagged with taskName and adds cronEntry, if not empty
+func replaceCrontabTask(taskName, cronEntry string) error {
 	getCurrentCmd := exec.Command("crontab", "-l")
 	currentCrontab, _ := getCurrentCmd.Output()
 
@@ -475,7 +959,9 @@ func createUnixTask(taskName, command string, runTime time.Time) error {
 			newLines = append(newLines, line)
 		}
 	}
-	newLines = append(newLines, cronEntry)
+	if cronEntry != "" {
+		newLines = append(newLines, cronEntry)
+	}
 
 	newCrontab := strings.Join(newLines, "\n") + "\n"
 	setCrontabCmd := exec.Command("crontab", "-")
@@ -487,6 +973,20 @@ func createUnixTask(taskName, command string, runTime time.Time) error {
 	return nil
 }
 
+func deleteScheduledTask(taskName string) error {
+	switch runtime.GOOS {
+	case "windows":
+		psScript := fmt.Sprintf(`Unregister-ScheduledTask -TaskName '%s' -Confirm:$false -ErrorAction SilentlyContinue`, taskName)
+		deleteCmd := exec.Command("powershell", "-NoProfile", "-Command", psScript)
+		if output, err := deleteCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete task: %v, output: %s", err, string(output))
+		}
+		return nil
+	default:
+		return replaceCrontabTask(taskName, "")
+	}
+}
+
 // Returns the path then the actual program
 // Windows will throw some errors if the program is launched outside of the executable's directory
 func getOBSPath() string {
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"launcher/internal/state"
+	"testing"
+	"time"
+)
+
+func TestRescheduleTasks(t *testing.T) {
+	baseDir := t.TempDir()
+	start := time.Date(2026, 10, 17, 7, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 10, 17, 18, 30, 0, 0, time.UTC)
+	err := openStateStore(baseDir).Update(func(st *state.State) error {
+		st.Add(&state.Broadcast{ID: "tomorrow", PlannedStart: start.Add(24 * time.Hour)})
+		st.Add(&state.Broadcast{ID: "today", PlannedStart: start})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := func(id string) (*state.Broadcast, string) {
+		var b state.Broadcast
+		var current string
+		openStateStore(baseDir).View(func(st *state.State) error {
+			b = *st.Find(id)
+			current = st.Current
+			return nil
+		})
+		return &b, current
+	}
+
+	var registered []time.Time
+	register := func(start, end time.Time) error {
+		registered = append(registered, start, end)
+		return nil
+	}
+
+	// Another broadcast only gets its new times recorded; the tasks stay with the current one
+	newStart, newEnd := start.Add(25*time.Hour), end.Add(24*time.Hour)
+	moved, err := rescheduleTasks(baseDir, "tomorrow", newStart, newEnd, register)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved || len(registered) != 0 {
+		t.Errorf("rescheduling a broadcast that isn't current moved the tasks to %v", registered)
+	}
+	b, current := recorded("tomorrow")
+	if !b.PlannedStart.Equal(newStart) || !b.PlannedEnd.Equal(newEnd) {
+		t.Errorf("recorded %s to %s, want %s to %s", b.PlannedStart, b.PlannedEnd, newStart, newEnd)
+	}
+	if current != "today" {
+		t.Errorf("current broadcast = %q, want today", current)
+	}
+
+	// The current broadcast moves the tasks with it
+	moved, err = rescheduleTasks(baseDir, "today", start.Add(time.Hour), end, register)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !moved || len(registered) != 2 || !registered[0].Equal(start.Add(time.Hour)) || !registered[1].Equal(end) {
+		t.Errorf("moved = %v, registered %v", moved, registered)
+	}
+	if b, _ := recorded("today"); !b.PlannedStart.Equal(start.Add(time.Hour)) {
+		t.Errorf("recorded start %s, want %s", b.PlannedStart, start.Add(time.Hour))
+	}
+
+	// Tasks that couldn't be registered don't get recorded as the new schedule
+	failed := errors.New("crontab not found")
+	if _, err := rescheduleTasks(baseDir, "today", start.Add(2*time.Hour), end, func(time.Time, time.Time) error { return failed }); !errors.Is(err, failed) {
+		t.Errorf("error = %v, want %v", err, failed)
+	}
+	if b, _ := recorded("today"); !b.PlannedStart.Equal(start.Add(time.Hour)) {
+		t.Errorf("failed reschedule recorded start %s", b.PlannedStart)
+	}
+}
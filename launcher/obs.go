@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"launcher/internal/obsws"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	obsPasswordEnv = "OBS_WEBSOCKET_PASSWORD"
+	// obsStartupDelay is how long OBS gets to bring up its WebSocket server after launch
+	obsStartupDelay = 30 * time.Second
+	obsDialInterval = 2 * time.Second
+)
+
+type obsOptions struct {
+	path     string
+	address  string
+	password string
+	timeout  time.Duration
+	// assumeStreaming goes on without obs-websocket if it still can't be reached after
+	// launching OBS, like before obs-websocket support
+	assumeStreaming bool
+}
+
+// startOBSStream makes sure OBS is running and pushing the stream. OBS is launched
+// if it isn't running, the stream output is started if it isn't active yet, and we
+// wait for OBS to report OUTPUT_STARTED.
+func startOBSStream(opts obsOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	client, err := obsws.Dial(ctx, opts.address, opts.password, obsws.EventOutputs)
+	if errors.Is(err, obsws.ErrAuthenticationFailed) {
+		return fmt.Errorf("%v (set --obs-password or %s)", err, obsPasswordEnv)
+	}
+	if err != nil {
+		// Only launch OBS if it isn't running, so an unreachable obs-websocket doesn't
+		// start a second copy
+		running, psErr := obsRunning(opts.path)
+		if psErr != nil {
+			return fmt.Errorf("%v (could not check whether OBS is running: %v)", err, psErr)
+		}
+		if running {
+			return fmt.Errorf("OBS is running but %v; enable it in OBS under Tools -> WebSocket Server Settings", err)
+		}
+
+		fmt.Printf("Starting OBS in directory: %s\n", opts.path)
+
+		obsCmd := exec.Command(opts.path, "--startstreaming")
+		obsCmd.Dir = filepath.Dir(opts.path)
+		if err := obsCmd.Start(); err != nil {
+			return fmt.Errorf("error starting OBS: %v", err)
+		}
+		fmt.Println("OBS started with streaming enabled")
+
+		client, err = dialOBS(ctx, opts, obsStartupDelay)
+		if errors.Is(err, obsws.ErrAuthenticationFailed) {
+			return fmt.Errorf("%v (set --obs-password or %s)", err, obsPasswordEnv)
+		}
+		if err != nil && opts.assumeStreaming {
+			fmt.Fprintf(os.Stderr, "Warning: Could not reach obs-websocket at %s, assuming OBS is streaming: %v\n", opts.address, err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("OBS was started but %v; enable it in OBS under Tools -> WebSocket Server Settings, or pass --obs-assume-streaming", err)
+		}
+	}
+	defer client.Close()
+	fmt.Printf("Connected to obs-websocket at %s\n", opts.address)
+
+	status, err := client.GetStreamStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting OBS stream status: %v", err)
+	}
+	if status.OutputActive {
+		fmt.Println("OBS is already streaming")
+		return nil
+	}
+
+	fmt.Println("Starting OBS stream output...")
+	if err := client.StartStream(ctx); err != nil {
+		return fmt.Errorf("error starting OBS stream output: %v", err)
+	}
+	if err := client.WaitForStreamState(ctx, obsws.OutputStarted); err != nil {
+		return fmt.Errorf("error waiting for OBS stream output to start: %v", err)
+	}
+	fmt.Println("OBS stream output started")
+
+	return nil
+}
+
+// dialOBS retries connecting to obs-websocket until it succeeds, authentication
+// fails, or wait has elapsed
+func dialOBS(ctx context.Context, opts obsOptions, wait time.Duration) (*obsws.Client, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	for {
+		client, err := obsws.Dial(dialCtx, opts.address, opts.password, obsws.EventOutputs)
+		if err == nil || errors.Is(err, obsws.ErrAuthenticationFailed) {
+			return client, err
+		}
+
+		select {
+		case <-dialCtx.Done():
+			return nil, err
+		case <-time.After(obsDialInterval):
+		}
+	}
+}
+
+// obsRunning reports whether a process with the OBS executable's name is running
+func obsRunning(path string) (bool, error) {
+	name := filepath.Base(path)
+	switch runtime.GOOS {
+	case "windows":
+		out, err := exec.Command("tasklist", "/FI", "IMAGENAME eq "+name, "/NH").Output()
+		if err != nil {
+			return false, err
+		}
+		return strings.Contains(strings.ToLower(string(out)), strings.ToLower(name)), nil
+	default:
+		err := exec.Command("pgrep", "-x", name).Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return err == nil, err
+	}
+}
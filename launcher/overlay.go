@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/overlay"
+	"launcher/internal/wx"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func printOverlayUsage() {
+	fmt.Println("OBS browser source overlay")
+	fmt.Println()
+	fmt.Println("Usage: launcher overlay <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  serve  Serve the wind overlay for an OBS browser source")
+	fmt.Println()
+	fmt.Println("Run 'launcher overlay <command> --help' for more information.")
+}
+
+// cmdOverlay handles the overlay subcommand
+func cmdOverlay(args []string) {
+	if len(args) < 1 {
+		printOverlayUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "serve":
+		cmdOverlayServe(args[1:])
+	case "-help", "--help", "help":
+		printOverlayUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown overlay command: %s\n\n", args[0])
+		printOverlayUsage()
+		os.Exit(1)
+	}
+}
+
+// stationHoursFlags adds the options for the station's daily reporting hours
+func stationHoursFlags(fs *flag.FlagSet) func() (wx.Window, error) {
+	begin := fs.String("station-begin", wx.DefaultBegin, "Time of day (HH:MM) the station starts reporting")
+	end := fs.String("station-end", wx.DefaultEnd, "Time of day (HH:MM) the station stops reporting")
+	return func() (wx.Window, error) {
+		return wx.ParseWindow(*begin, *end)
+	}
+}
+
+// stationReading polls the station for the overlay. Outside the station's hours the
+// station isn't read at all, matching weather_data.lua.
+func stationReading(ctx context.Context, client *wx.Client, hours wx.Window, now time.Time) overlay.Reading {
+	reading := overlay.Reading{Updated: now}
+	if !hours.Contains(now) {
+		reading.Reason = "offline"
+		return reading
+	}
+
+	rec, err := client.Latest(ctx)
+	if errors.Is(err, wx.ErrNoData) {
+		reading.Reason = "no data"
+		return reading
+	}
+	if err != nil {
+		log.Printf("Could not read the weather station: %v", err)
+		reading.Reason = "station unreachable"
+		return reading
+	}
+
+	reading.Online = true
+	reading.Time = rec.Time
+	reading.Wind = rec.Wind
+	reading.Gust = rec.Gust
+	reading.Direction = rec.Direction
+	reading.Cardinal = rec.Cardinal()
+	return reading
+}
+
+func cmdOverlayServe(args []string) {
+	fs := flag.NewFlagSet("overlay serve", flag.ExitOnError)
+	client := stationFlags(fs)
+	hoursFlag := stationHoursFlags(fs)
+	interval := fs.Duration("station-interval", time.Minute, "How often to read the weather station")
+	address := fs.String("overlay-address", "127.0.0.1:8090", "Address to serve the overlay on")
+	cssPath := fs.String("overlay-css", "", "CSS file to restyle the overlay, served after the default style")
+	fs.Usage = func() { printFlagUsage(fs, "launcher overlay serve") }
+	parseFlags(fs, args)
+
+	hours, err := hoursFlag()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *interval < time.Second {
+		fmt.Fprintln(os.Stderr, "Error: --station-interval must be at least 1s")
+		os.Exit(1)
+	}
+
+	server := &overlay.Server{}
+	if *cssPath != "" {
+		if server.CSS, err = os.ReadFile(*cssPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading overlay CSS: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		station := client()
+		for {
+			server.Publish(stationReading(ctx, station, hours, time.Now()))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(*interval):
+			}
+		}
+	}()
+
+	// Requests share ctx, so open event streams end on shutdown
+	httpServer := &http.Server{Handler: server.Handler(), BaseContext: func(net.Listener) context.Context { return ctx }}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Serving the wind overlay at http://%s/ (add it to OBS as a browser source)", listener.Addr())
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Overlay server stopped: %v", err)
+	}
+	log.Println("Overlay server stopped")
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/auth"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultProfileName = "default"
+	configDirName      = "obs-launcher"
+	// legacyProfileFile held a profile's settings before config.yaml
+	legacyProfileFile = "profile.json"
+
+	defaultTitleTemplate = "Marshall WX ({date})"
+	defaultStartOffset   = -30
+	defaultEndOffset     = 30
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Profile is one channel's settings. The default profile lives next to the executable so
+// existing installs keep working; named profiles live under the user's config directory,
+// each with its own credentials, token, history and recurring schedule.
+type Profile struct {
+	Name string
+	Dir  string
+	// Config is the profile's config.yaml, keyed by the names in settings
+	Config map[string]interface{}
+
+	// store is the token store, once tokenStore has made it
+	store auth.TokenStore
+	// doc is config.yaml as read, so save can keep its comments
+	doc *yaml.Node
+}
+
+// activeProfile is selected with the global --profile option
+var activeProfile *Profile
+
+// profilesDir returns the directory named profiles are stored in
+func profilesDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %v", err)
+	}
+	return filepath.Join(configDir, configDirName, "profiles"), nil
+}
+
+func profileDir(name string) (string, error) {
+	if name == defaultProfileName {
+		return executableDir(), nil
+	}
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name '%s' (use lowercase letters, digits, '-' and '_')", name)
+	}
+	dir, err := profilesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// loadProfile reads a profile's settings. A profile without a config.yaml uses the defaults.
+func loadProfile(name string) (*Profile, error) {
+	dir, err := profileDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Profile{Name: name, Dir: dir, Config: map[string]interface{}{}}
+	data, err := os.ReadFile(p.configPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		// The node tree is kept so saving doesn't lose comments in a hand-edited file
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", p.configPath(), err)
+		}
+		if doc.Kind != 0 {
+			if err := doc.Decode(&p.Config); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %v", p.configPath(), err)
+			}
+			p.doc = &doc
+		}
+		if p.Config == nil {
+			p.Config = map[string]interface{}{}
+		}
+	}
+
+	p.importLegacyProfile()
+	return p, nil
+}
+
+// importLegacyProfile moves the settings of an older profile.json into config.yaml, so a
+// profile's stream title, token store and the rest survive the switch to config.yaml.
+// Settings already in config.yaml win.
+func (p *Profile) importLegacyProfile() {
+	legacyPath := filepath.Join(p.Dir, legacyProfileFile)
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return
+	}
+
+	var legacy map[string]interface{}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not import %s: %v\n", legacyPath, err)
+		return
+	}
+	for key, value := range legacy {
+		if _, ok := lookupSetting(key); !ok {
+			fmt.Fprintf(os.Stderr, "Warning: Ignoring unknown setting '%s' in %s\n", key, legacyPath)
+			continue
+		}
+		if _, ok := p.Config[key]; ok {
+			continue
+		}
+		// JSON numbers decode as float64; the offsets are whole minutes
+		if f, ok := value.(float64); ok && f == math.Trunc(f) {
+			value = int(f)
+		}
+		p.Config[key] = value
+	}
+
+	if err := p.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not import %s into %s: %v\n", legacyProfileFile, configFile, err)
+		return
+	}
+	if err := os.Rename(legacyPath, legacyPath+".imported"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not rename %s: %v\n", legacyPath, err)
+	}
+	fmt.Fprintf(os.Stderr, "Imported %s into %s\n", legacyPath, p.configPath())
+}
+
+func (p *Profile) configPath() string {
+	return filepath.Join(p.Dir, configFile)
+}
+
+// save writes the profile's config.yaml. The file's node tree is edited rather than
+// replaced, so comments and the order of existing keys are kept.
+func (p *Profile) save() error {
+	if err := os.MkdirAll(p.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %v", err)
+	}
+	if p.doc == nil {
+		p.doc = &yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if err := syncConfigNode(p.doc, p.Config); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(p.doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(p.configPath(), buf.Bytes(), 0600)
+}
+
+// syncConfigNode makes doc's top-level mapping hold config. Unchanged values keep their
+// node, changed ones keep the comments around them, removed keys go and new keys are
+// added at the end in name order.
+func syncConfigNode(doc *yaml.Node, config map[string]interface{}) error {
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return errors.New("config file is not a mapping of settings")
+	}
+
+	seen := map[string]bool{}
+	var content []*yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		keyNode, valueNode := mapping.Content[i], mapping.Content[i+1]
+		value, ok := config[keyNode.Value]
+		if !ok {
+			continue
+		}
+		seen[keyNode.Value] = true
+
+		var current interface{}
+		if err := valueNode.Decode(&current); err != nil || !reflect.DeepEqual(current, value) {
+			var updated yaml.Node
+			if err := updated.Encode(value); err != nil {
+				return err
+			}
+			updated.HeadComment = valueNode.HeadComment
+			updated.LineComment = valueNode.LineComment
+			updated.FootComment = valueNode.FootComment
+			valueNode = &updated
+		}
+		content = append(content, keyNode, valueNode)
+	}
+
+	var added []string
+	for key := range config {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		var valueNode yaml.Node
+		if err := valueNode.Encode(config[key]); err != nil {
+			return err
+		}
+		content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &valueNode)
+	}
+	mapping.Content = content
+	return nil
+}
+
+func (p *Profile) isDefault() bool {
+	return p.Name == defaultProfileName
+}
+
+// streamTitle names the reusable YouTube live stream (ingest key), not the broadcast
+func (p *Profile) streamTitle() string {
+	return p.settingValue("stream_title")
+}
+
+func (p *Profile) titleTemplate() string {
+	return p.settingValue("title_template")
+}
+
+// broadcastTitle renders the profile's title template. The template can use the same
+// actions as --title; {date} is kept as a shorthand for the start date as MM/DD/YYYY.
+func (p *Profile) broadcastTitle(data broadcastText) (string, error) {
+	return renderText("title_template", strings.ReplaceAll(p.titleTemplate(), "{date}", data.Date.Format("01/02/2006")), data)
+}
+
+// taskName keeps each profile's scheduled tasks apart, e.g. StartYouTubeStream-north-ridge
+func (p *Profile) taskName(base string) string {
+	if p.isDefault() {
+		return base
+	}
+	return base + "-" + p.Name
+}
+
+// commandPrefix returns the executable invocation for scheduled tasks, with the profile selected
+func (p *Profile) commandPrefix(execPath string) string {
+	if p.isDefault() {
+		return fmt.Sprintf(`"%s"`, execPath)
+	}
+	return fmt.Sprintf(`"%s" --profile %s`, execPath, p.Name)
+}
+
+// profileEnv selects the profile when --profile isn't given
+const profileEnv = "LAUNCHER_PROFILE"
+
+// extractProfileFlag removes the global --profile option from the front of args. Only the
+// arguments before the command are global, so a command's own arguments are left alone even if
+// one of them is "--profile".
+func extractProfileFlag(args []string) ([]string, string, error) {
+	name := defaultProfileName
+	if env := os.Getenv(profileEnv); env != "" {
+		name = env
+	}
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--profile" || arg == "-profile":
+			if len(args) < 2 {
+				return nil, "", errors.New("--profile requires a name")
+			}
+			name = args[1]
+			args = args[2:]
+		case strings.HasPrefix(arg, "--profile="):
+			name = strings.TrimPrefix(arg, "--profile=")
+			args = args[1:]
+		case strings.HasPrefix(arg, "-profile="):
+			name = strings.TrimPrefix(arg, "-profile=")
+			args = args[1:]
+		default:
+			return args, name, nil
+		}
+	}
+	return args, name, nil
+}
+
+func printProfileUsage() {
+	fmt.Println("Channel profile commands")
+	fmt.Println()
+	fmt.Println("Usage: launcher profile <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list    List profiles")
+	fmt.Println("  show    Show a profile's settings")
+	fmt.Println("  set     Create a profile or change its settings")
+	fmt.Println("  delete  Delete a named profile and everything stored in it")
+	fmt.Println()
+	fmt.Println("Select a profile for any command with the global --profile option, e.g.")
+	fmt.Println("  launcher --profile north-ridge stream schedule")
+}
+
+// cmdProfile handles the profile subcommand
+func cmdProfile(args []string) {
+	if len(args) < 1 {
+		printProfileUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		cmdProfileList(args[1:])
+	case "show":
+		cmdProfileShow(args[1:])
+	case "set":
+		cmdProfileSet(args[1:])
+	case "delete":
+		cmdProfileDelete(args[1:])
+	case "-help", "--help", "help":
+		printProfileUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown profile command: %s\n\n", args[0])
+		printProfileUsage()
+		os.Exit(1)
+	}
+}
+
+func cmdProfileList(args []string) {
+	fs := flag.NewFlagSet("profile list", flag.ExitOnError)
+	fs.Usage = func() { printFlagUsage(fs, "launcher profile list") }
+	fs.Parse(args)
+
+	names := []string{defaultProfileName}
+	dir, err := profilesDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Error reading profiles: %v\n", err)
+		os.Exit(1)
+	}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != defaultProfileName {
+			names = append(names, e.Name())
+		}
+	}
+
+	for _, name := range names {
+		marker := " "
+		if name == activeProfile.Name {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+}
+
+// profileArg returns the profile named by the first argument, or the active profile
+func profileArg(fs *flag.FlagSet) *Profile {
+	if fs.NArg() == 0 {
+		return activeProfile
+	}
+	p, err := loadProfile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return p
+}
+
+func cmdProfileShow(args []string) {
+	fs := flag.NewFlagSet("profile show", flag.ExitOnError)
+	fs.Usage = func() { printFlagUsage(fs, "launcher profile show [name]") }
+	fs.Parse(args)
+
+	p := profileArg(fs)
+	city := p.settingValue("city")
+	if city == "" {
+		city = "(from IP address)"
+	}
+	fmt.Printf("Profile:         %s\n", p.Name)
+	fmt.Printf("Directory:       %s\n", p.Dir)
+	fmt.Printf("Stream title:    %s\n", p.streamTitle())
+	now := time.Now()
+	if title, err := p.broadcastTitle(broadcastText{Date: now, Start: now}); err != nil {
+		fmt.Printf("Title template:  %s (%v)\n", p.titleTemplate(), err)
+	} else {
+		fmt.Printf("Title template:  %s (today: %s)\n", p.titleTemplate(), title)
+	}
+	fmt.Printf("City:            %s\n", city)
+	fmt.Printf("Start offset:    %s min\n", p.settingValue("start_offset"))
+	fmt.Printf("End offset:      %s min\n", p.settingValue("end_offset"))
+	if store, err := p.tokenStore(); err != nil {
+		fmt.Printf("Token store:     %v\n", err)
+	} else {
+		fmt.Printf("Token store:     %s\n", store)
+	}
+}
+
+func cmdProfileSet(args []string) {
+	fs := flag.NewFlagSet("profile set", flag.ExitOnError)
+	streamTitle := fs.String("stream-title", "", "Title of the reusable YouTube live stream (ingest key) for this channel")
+	city := fs.String("city", "", "Default city for sunrise/sunset lookup")
+	startOffset := fs.Int("start-offset", defaultStartOffset, "Default minutes offset from the start sun event")
+	endOffset := fs.Int("end-offset", defaultEndOffset, "Default minutes offset from the end sun event")
+	titleTemplate := fs.String("title-template", "", "Broadcast title template; {date} is replaced with MM/DD/YYYY (default: '"+defaultTitleTemplate+"')")
+	tokenStore := fs.String("token-store", "", "Where the OAuth token is kept: "+strings.Join(tokenStores, ", ")+" (use 'auth migrate' to move an existing token) (default: file)")
+	fs.Usage = func() { printFlagUsage(fs, "launcher profile set [name]") }
+
+	// Allow the name before the options, e.g. 'profile set north-ridge --city Marshall'
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+
+	p := activeProfile
+	if name != "" {
+		var err error
+		if p, err = loadProfile(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *tokenStore != "" && !validTokenStore(*tokenStore) {
+		fmt.Fprintf(os.Stderr, "Error: --token-store must be one of %s\n", strings.Join(tokenStores, ", "))
+		os.Exit(1)
+	}
+
+	// Only the options given are changed; an empty value removes the key
+	set := func(key string, value interface{}) {
+		if value == "" {
+			delete(p.Config, key)
+		} else {
+			p.Config[key] = value
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "stream-title":
+			set("stream_title", *streamTitle)
+		case "city":
+			set("city", *city)
+		case "start-offset":
+			set("start_offset", *startOffset)
+		case "end-offset":
+			set("end_offset", *endOffset)
+		case "title-template":
+			set("title_template", *titleTemplate)
+		case "token-store":
+			set("token_store", *tokenStore)
+		}
+	})
+
+	_, statErr := os.Stat(p.Dir)
+	isNew := errors.Is(statErr, os.ErrNotExist)
+
+	if err := p.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving profile: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved profile '%s' to %s\n", p.Name, p.Dir)
+	if isNew {
+		fmt.Printf("Sign in to its channel with: launcher --profile %s auth login\n", p.Name)
+	}
+}
+
+func cmdProfileDelete(args []string) {
+	fs := flag.NewFlagSet("profile delete", flag.ExitOnError)
+	fs.Usage = func() { printFlagUsage(fs, "launcher profile delete <name>") }
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+	if name == defaultProfileName {
+		fmt.Fprintln(os.Stderr, "Error: the default profile can't be deleted")
+		os.Exit(1)
+	}
+
+	p, err := loadProfile(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(p.Dir); errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Error: profile '%s' does not exist\n", name)
+		os.Exit(1)
+	}
+	if err := os.RemoveAll(p.Dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error deleting profile: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted profile '%s'\n", name)
+}
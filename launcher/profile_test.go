@@ -0,0 +1,189 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testProfile loads a named profile from a temporary config directory, after writing files into it
+func testProfile(t *testing.T, files map[string]string) *Profile {
+	t.Helper()
+	// os.UserConfigDir reads one of these, depending on the OS
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	t.Setenv("AppData", configDir)
+	dir, err := profileDir("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := loadProfile("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func readConfig(t *testing.T, p *Profile) string {
+	t.Helper()
+	data, err := os.ReadFile(p.configPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSaveKeepsComments(t *testing.T) {
+	p := testProfile(t, map[string]string{configFile: `# North Ridge camera
+city: Marshall, NC # the town, not the ridge
+
+# Sunrise shots need the extra time
+start_offset: -45
+privacy: unlisted
+`})
+
+	p.Config["start_offset"] = -60
+	delete(p.Config, "privacy")
+	p.Config["tags"] = []string{"weather", "wind"}
+	if err := p.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readConfig(t, p)
+	for _, want := range []string{
+		"# North Ridge camera",
+		"city: Marshall, NC # the town, not the ridge",
+		"# Sunrise shots need the extra time\nstart_offset: -60",
+		"tags:\n  - weather\n  - wind",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config.yaml lost %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "privacy") {
+		t.Errorf("config.yaml still has the removed key:\n%s", got)
+	}
+	if strings.Index(got, "city") > strings.Index(got, "start_offset") {
+		t.Errorf("existing keys were reordered:\n%s", got)
+	}
+
+	reloaded, err := loadProfile("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.settingValue("start_offset") != "-60" || reloaded.settingValue("tags") != "weather,wind" {
+		t.Errorf("reloaded settings: start_offset=%s tags=%s", reloaded.settingValue("start_offset"), reloaded.settingValue("tags"))
+	}
+}
+
+func TestSaveNewConfig(t *testing.T) {
+	p := testProfile(t, nil)
+	p.Config["stream_title"] = "North Ridge - Stream"
+	p.Config["city"] = "Marshall, NC"
+	if err := p.save(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readConfig(t, p), "city: Marshall, NC\nstream_title: North Ridge - Stream\n"; got != want {
+		t.Errorf("config.yaml = %q, want %q", got, want)
+	}
+}
+
+func TestImportLegacyProfile(t *testing.T) {
+	p := testProfile(t, map[string]string{
+		legacyProfileFile: `{"stream_title": "North Ridge - Stream", "city": "Asheville, NC", "start_offset": -45, "token_store": "keyring"}`,
+		configFile:        "# set by hand\ncity: Marshall, NC\n",
+	})
+
+	for key, want := range map[string]string{
+		"stream_title": "North Ridge - Stream",
+		"city":         "Marshall, NC", // config.yaml wins
+		"start_offset": "-45",
+		"token_store":  "keyring",
+	} {
+		if got := p.settingValue(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	got := readConfig(t, p)
+	if !strings.Contains(got, "# set by hand") || !strings.Contains(got, "start_offset: -45\n") {
+		t.Errorf("config.yaml after import:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(p.Dir, legacyProfileFile)); !os.IsNotExist(err) {
+		t.Errorf("%s is still there after the import", legacyProfileFile)
+	}
+
+	// Loading again doesn't import again
+	reloaded, err := loadProfile("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.settingValue("token_store") != "keyring" {
+		t.Errorf("token_store after reload = %q", reloaded.settingValue("token_store"))
+	}
+}
+
+func TestExtractProfileFlag(t *testing.T) {
+	t.Setenv(profileEnv, "")
+	tests := []struct {
+		name    string
+		args    []string
+		rest    []string
+		profile string
+		wantErr bool
+	}{
+		{name: "none", args: []string{"stream", "start"}, rest: []string{"stream", "start"}, profile: defaultProfileName},
+		{name: "before the command", args: []string{"--profile", "north-ridge", "stream", "start"}, rest: []string{"stream", "start"}, profile: "north-ridge"},
+		{name: "with equals", args: []string{"--profile=north-ridge", "auth", "login"}, rest: []string{"auth", "login"}, profile: "north-ridge"},
+		{name: "single dash", args: []string{"-profile", "north-ridge", "version"}, rest: []string{"version"}, profile: "north-ridge"},
+		{name: "last one wins", args: []string{"-profile=a", "--profile", "b", "sunrise"}, rest: []string{"sunrise"}, profile: "b"},
+		{
+			name:    "value of a command's flag",
+			args:    []string{"stream", "schedule", "--title", "--profile", "--profile=x"},
+			rest:    []string{"stream", "schedule", "--title", "--profile", "--profile=x"},
+			profile: defaultProfileName,
+		},
+		{
+			name:    "after the command",
+			args:    []string{"--profile", "north-ridge", "profile", "show", "--profile", "other"},
+			rest:    []string{"profile", "show", "--profile", "other"},
+			profile: "north-ridge",
+		},
+		{name: "before a global flag", args: []string{"--profile", "north-ridge", "--help"}, rest: []string{"--help"}, profile: "north-ridge"},
+		{name: "only the profile", args: []string{"--profile", "north-ridge"}, rest: []string{}, profile: "north-ridge"},
+		{name: "missing name", args: []string{"--profile"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, profile, err := extractProfileFlag(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("extractProfileFlag(%q) succeeded, want an error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(rest, " ") != strings.Join(tt.rest, " ") || len(rest) != len(tt.rest) || profile != tt.profile {
+				t.Errorf("extractProfileFlag(%q) = %q, %q, want %q, %q", tt.args, rest, profile, tt.rest, tt.profile)
+			}
+		})
+	}
+
+	t.Setenv(profileEnv, "from-env")
+	if _, profile, _ := extractProfileFlag([]string{"stream", "start"}); profile != "from-env" {
+		t.Errorf("profile = %q, want $%s", profile, profileEnv)
+	}
+}
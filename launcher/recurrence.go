@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const recurrenceFile = "recurrence.json"
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Recurrence is a repeating stream schedule. It is saved by 'stream schedule --recur'
+// and read by 'stream end' to schedule the next occurrence.
+type Recurrence struct {
+	// Days holds weekday abbreviations (mon, tue, ...); empty means every day
+	Days     []string        `json:"days,omitempty"`
+	Schedule scheduleOptions `json:"schedule"`
+}
+
+func newRecurrence(opts scheduleOptions, days string) (*Recurrence, error) {
+	for _, anchor := range []string{opts.StartTime, opts.EndTime} {
+		if _, ok := parseSunEvent(anchor); !ok {
+			return nil, fmt.Errorf("recurring streams need sun event start/end times (%s), got '%s'", strings.Join(sunEvents, ", "), anchor)
+		}
+	}
+
+	r := &Recurrence{Schedule: opts}
+	if days != "" {
+		for _, token := range strings.Split(days, ",") {
+			day, ok := parseWeekday(token)
+			if !ok {
+				return nil, fmt.Errorf("unknown day '%s' in --days (expected e.g. 'mon,wed,sat' or 'monday,wednesday')", strings.TrimSpace(token))
+			}
+			r.Days = append(r.Days, day)
+		}
+	}
+	return r, nil
+}
+
+// parseWeekday returns the abbreviation of a weekday given by its abbreviation or full name,
+// in any case
+func parseWeekday(day string) (string, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	for i, name := range weekdayNames {
+		if day == name || day == strings.ToLower(time.Weekday(i).String()) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func weekdayIndex(day string) int {
+	for i, name := range weekdayNames {
+		if name == day {
+			return i
+		}
+	}
+	return -1
+}
+
+// runsOn reports whether the recurrence includes date's weekday
+func (r *Recurrence) runsOn(date time.Time) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, day := range r.Days {
+		if weekdayIndex(day) == int(date.Weekday()) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstDate returns the first day from now on whose stream hasn't ended yet
+func (r *Recurrence) firstDate(now time.Time) (time.Time, error) {
+	date := now
+	for i := 0; i < 8; i++ {
+		if r.runsOn(date) {
+			plan, err := planStream(r.Schedule, date)
+			if err != nil {
+				return time.Time{}, err
+			}
+			if plan.End.After(now) {
+				return date, nil
+			}
+		}
+		date = nextCalendarDay(date)
+	}
+	return time.Time{}, errors.New("no upcoming day matches the recurrence")
+}
+
+// nextDate returns the first matching day after date's calendar day
+func (r *Recurrence) nextDate(date time.Time) time.Time {
+	for i := 0; i < 7; i++ {
+		date = nextCalendarDay(date)
+		if r.runsOn(date) {
+			break
+		}
+	}
+	return date
+}
+
+func (r *Recurrence) describeDays() string {
+	if len(r.Days) == 0 {
+		return "every day"
+	}
+	return strings.Join(r.Days, ", ")
+}
+
+// nextCalendarDay returns noon of the following day, which stays on the right date across DST changes
+func nextCalendarDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 12, 0, 0, 0, t.Location())
+}
+
+func loadRecurrence(baseDir string) (*Recurrence, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, recurrenceFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r Recurrence
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", recurrenceFile, err)
+	}
+	return &r, nil
+}
+
+func saveRecurrence(baseDir string, r *Recurrence) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(baseDir, recurrenceFile), data, 0644)
+}
+
+func clearRecurrence(baseDir string) error {
+	err := os.Remove(filepath.Join(baseDir, recurrenceFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// scheduleNextOccurrence schedules the first stream of a recurring schedule after
+// after's calendar day, if there is a recurring schedule
+func scheduleNextOccurrence(baseDir, execPath string, after time.Time) error {
+	r, err := loadRecurrence(baseDir)
+	if err != nil || r == nil {
+		return err
+	}
+
+	date := r.nextDate(after)
+	fmt.Println()
+	fmt.Printf("=== Scheduling Next Occurrence (%s) ===\n", date.Format("Mon 2006-01-02"))
+	fmt.Println()
+
+	return scheduleStreamOn(baseDir, execPath, r.Schedule, date)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func sunOptions() scheduleOptions {
+	// Coordinates need no lookup; Greenwich sets around 17:15 UTC in mid-October
+	return scheduleOptions{City: "51.4779,-0.0015", StartTime: "SUNRISE", EndTime: "SUNSET"}
+}
+
+func TestNewRecurrence(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    scheduleOptions
+		days    string
+		want    string
+		wantErr string
+	}{
+		{name: "every day", opts: sunOptions(), days: "", want: ""},
+		{name: "abbreviations", opts: sunOptions(), days: "mon,wed,sat", want: "mon,wed,sat"},
+		{name: "full names", opts: sunOptions(), days: "Monday, WEDNESDAY ,saturday", want: "mon,wed,sat"},
+		{name: "mixed", opts: sunOptions(), days: "sun,Thursday", want: "sun,thu"},
+		{name: "longer than a name", opts: sunOptions(), days: "monxyz,sat", wantErr: "unknown day 'monxyz'"},
+		{name: "prefix of a name", opts: sunOptions(), days: "mon,satan", wantErr: "unknown day 'satan'"},
+		{name: "partial name", opts: sunOptions(), days: "wednes", wantErr: "unknown day 'wednes'"},
+		{name: "too short", opts: sunOptions(), days: "mo", wantErr: "unknown day 'mo'"},
+		{name: "empty day", opts: sunOptions(), days: "mon,,wed", wantErr: "unknown day ''"},
+		{
+			name:    "fixed start time",
+			opts:    scheduleOptions{StartTime: "2026-10-16T07:00:00", EndTime: "SUNSET"},
+			wantErr: "need sun event start/end times",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newRecurrence(tt.opts, tt.days)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(r.Days, ","); got != tt.want {
+				t.Errorf("days = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNextDate(t *testing.T) {
+	day := func(date string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", date)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	// 2026-10-14 is a Wednesday
+	tests := []struct {
+		name  string
+		days  []string
+		after time.Time
+		want  string
+	}{
+		{"every day", nil, day("2026-10-14 19:00"), "Thu 2026-10-15"},
+		{"next matching day", []string{"mon", "wed", "sat"}, day("2026-10-14 19:00"), "Sat 2026-10-17"},
+		{"wraps to next week", []string{"mon", "wed"}, day("2026-10-17 09:00"), "Mon 2026-10-19"},
+		{"same weekday a week later", []string{"wed"}, day("2026-10-14 06:00"), "Wed 2026-10-21"},
+		{"across the month end", []string{"sun"}, day("2026-10-30 19:00"), "Sun 2026-11-01"},
+		{"across the year end", []string{"fri"}, day("2026-12-26 19:00"), "Fri 2027-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Recurrence{Days: tt.days}
+			if got := r.nextDate(tt.after).Format("Mon 2006-01-02"); got != tt.want {
+				t.Errorf("nextDate(%s) = %s, want %s", tt.after.Format("Mon 2006-01-02 15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNextDateDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone America/New_York not available: %v", err)
+	}
+	// US clocks go back on Sunday 2026-11-01
+	r := &Recurrence{Days: []string{"sun", "mon"}}
+	date := time.Date(2026, 10, 31, 23, 30, 0, 0, newYork)
+	for _, want := range []string{"Sun 2026-11-01", "Mon 2026-11-02", "Sun 2026-11-08"} {
+		date = r.nextDate(date)
+		if got := date.Format("Mon 2006-01-02"); got != want {
+			t.Fatalf("nextDate = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestRecurrenceFirstDate(t *testing.T) {
+	useProfile(t, testProfile(t, nil))
+
+	tests := []struct {
+		name string
+		days string
+		now  time.Time
+		want string
+	}{
+		{"today before sunset", "", time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), "Wed 2026-10-14"},
+		{"today after sunset", "", time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC), "Thu 2026-10-15"},
+		{"today not a stream day", "mon,sat", time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), "Sat 2026-10-17"},
+		{"after sunset on a stream day", "mon,wed", time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC), "Mon 2026-10-19"},
+		{"only day already over", "wednesday", time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC), "Wed 2026-10-21"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newRecurrence(sunOptions(), tt.days)
+			if err != nil {
+				t.Fatal(err)
+			}
+			date, err := r.firstDate(tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := date.Format("Mon 2006-01-02"); got != tt.want {
+				t.Errorf("firstDate = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,15 +4,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"launcher/internal/atomicfile"
+	"launcher/internal/solar"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
+const (
+	sunSourceNOAA = "noaa"
+	sunSourceAPI  = "api"
+)
+
 type SunriseSunsetResponse struct {
 	Results struct {
-		Sunrise string `json:"sunrise"`
-		Sunset  string `json:"sunset"`
+		Sunrise                   string `json:"sunrise"`
+		Sunset                    string `json:"sunset"`
+		SolarNoon                 string `json:"solar_noon"`
+		CivilTwilightBegin        string `json:"civil_twilight_begin"`
+		CivilTwilightEnd          string `json:"civil_twilight_end"`
+		NauticalTwilightBegin     string `json:"nautical_twilight_begin"`
+		NauticalTwilightEnd       string `json:"nautical_twilight_end"`
+		AstronomicalTwilightBegin string `json:"astronomical_twilight_begin"`
+		AstronomicalTwilightEnd   string `json:"astronomical_twilight_end"`
 	} `json:"results"`
 	Status string `json:"status"`
 }
@@ -31,6 +49,114 @@ type NominatimResponse struct {
 	Lon string `json:"lon"`
 }
 
+// locationsFile caches looked-up locations in the profile directory, so the sun times
+// can still be computed when the uplink is down
+const locationsFile = "locations.json"
+
+// ipLocationKey is the cache key of the location found from the machine's IP
+const ipLocationKey = "(ip)"
+
+type cachedLocation struct {
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+	Name string  `json:"name"`
+}
+
+// getLocation returns lat/lng and a display name for city, or for the machine's IP if city is empty.
+// city can also be "lat,lng", which needs no lookup. A city is only geocoded the first time;
+// the IP's location is looked up every time, falling back to the last one found.
+func getLocation(city string) (float64, float64, string, error) {
+	if lat, lng, ok := parseCoordinates(city); ok {
+		return lat, lng, city, nil
+	}
+	cache := loadLocationCache()
+
+	if city == "" {
+		lat, lng, locationName, err := getLocationFromIP()
+		if err != nil {
+			loc, ok := cache[ipLocationKey]
+			if !ok {
+				return 0, 0, "", err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: Could not look up location (%v), using the last one found: %s\n", err, loc.Name)
+			return loc.Lat, loc.Lng, loc.Name, nil
+		}
+		saveLocation(cache, ipLocationKey, cachedLocation{Lat: lat, Lng: lng, Name: locationName})
+		return lat, lng, locationName, nil
+	}
+
+	key := strings.ToLower(strings.TrimSpace(city))
+	if loc, ok := cache[key]; ok {
+		return loc.Lat, loc.Lng, city, nil
+	}
+	lat, lng, err := getLocationFromCity(city)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("failed to get location for city: %v", err)
+	}
+	saveLocation(cache, key, cachedLocation{Lat: lat, Lng: lng, Name: city})
+	return lat, lng, city, nil
+}
+
+// parseCoordinates parses a "lat,lng" location, e.g. "35.7973,-82.6840"
+func parseCoordinates(value string) (float64, float64, bool) {
+	latText, lngText, ok := strings.Cut(value, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+func locationCachePath() string {
+	if activeProfile == nil {
+		return ""
+	}
+	return filepath.Join(activeProfile.Dir, locationsFile)
+}
+
+// loadLocationCache returns the cached locations. A missing or unreadable cache is empty.
+func loadLocationCache() map[string]cachedLocation {
+	cache := map[string]cachedLocation{}
+	path := locationCachePath()
+	if path == "" {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not read %s: %v\n", path, err)
+		return map[string]cachedLocation{}
+	}
+	return cache
+}
+
+// saveLocation adds a location to the cache. Failing to cache only costs a lookup next time.
+func saveLocation(cache map[string]cachedLocation, key string, loc cachedLocation) {
+	path := locationCachePath()
+	if path == "" || cache[key] == loc {
+		return
+	}
+	cache[key] = loc
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			err = atomicfile.Write(path, data, 0600)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not cache location: %v\n", err)
+	}
+}
+
 func getLocationFromIP() (float64, float64, string, error) {
 	resp, err := http.Get("http://ip-api.com/json/")
 	if err != nil {
@@ -95,13 +221,130 @@ func getLocationFromCity(city string) (float64, float64, error) {
 	return lat, lng, nil
 }
 
+// Sun event names accepted by --event and as stream schedule anchors
+const (
+	sunEventAstronomicalDawn = "astronomical-dawn"
+	sunEventNauticalDawn     = "nautical-dawn"
+	sunEventCivilDawn        = "civil-dawn"
+	sunEventSunrise          = "sunrise"
+	sunEventSolarNoon        = "solar-noon"
+	sunEventSunset           = "sunset"
+	sunEventCivilDusk        = "civil-dusk"
+	sunEventNauticalDusk     = "nautical-dusk"
+	sunEventAstronomicalDusk = "astronomical-dusk"
+)
+
+// sunEvents lists every sun event in the order it occurs during the day
+var sunEvents = []string{
+	sunEventAstronomicalDawn,
+	sunEventNauticalDawn,
+	sunEventCivilDawn,
+	sunEventSunrise,
+	sunEventSolarNoon,
+	sunEventSunset,
+	sunEventCivilDusk,
+	sunEventNauticalDusk,
+	sunEventAstronomicalDusk,
+}
+
+// SunTimes holds the sun events of a day. Events that don't occur at the
+// location on that date (e.g. astronomical dusk in a high-latitude summer)
+// are zero.
 type SunTimes struct {
-	Sunrise time.Time
-	Sunset  time.Time
+	AstronomicalDawn time.Time
+	NauticalDawn     time.Time
+	CivilDawn        time.Time
+	Sunrise          time.Time
+	SolarNoon        time.Time
+	Sunset           time.Time
+	CivilDusk        time.Time
+	NauticalDusk     time.Time
+	AstronomicalDusk time.Time
+}
+
+// parseSunEvent normalizes a user-supplied event name (e.g. "CIVIL_DAWN") and
+// reports whether it is a known sun event
+func parseSunEvent(name string) (string, bool) {
+	event := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+	for _, e := range sunEvents {
+		if e == event {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// sunEventLabel returns a human-readable label for an event, e.g. "Civil dawn"
+func sunEventLabel(event string) string {
+	label := strings.ReplaceAll(event, "-", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
 }
 
-// getSunTimes fetches both sunrise and sunset times for a given location and date
-func getSunTimes(lat, lng float64, date time.Time) (*SunTimes, error) {
+// Event returns the time of the named sun event
+func (s *SunTimes) Event(event string) (time.Time, error) {
+	var t time.Time
+	switch event {
+	case sunEventAstronomicalDawn:
+		t = s.AstronomicalDawn
+	case sunEventNauticalDawn:
+		t = s.NauticalDawn
+	case sunEventCivilDawn:
+		t = s.CivilDawn
+	case sunEventSunrise:
+		t = s.Sunrise
+	case sunEventSolarNoon:
+		t = s.SolarNoon
+	case sunEventSunset:
+		t = s.Sunset
+	case sunEventCivilDusk:
+		t = s.CivilDusk
+	case sunEventNauticalDusk:
+		t = s.NauticalDusk
+	case sunEventAstronomicalDusk:
+		t = s.AstronomicalDusk
+	default:
+		return time.Time{}, fmt.Errorf("unknown sun event: %s (expected one of %s)", event, strings.Join(sunEvents, ", "))
+	}
+
+	// Polar day and night leave the events that don't happen as zero times
+	if t.IsZero() {
+		return time.Time{}, fmt.Errorf("%s does not occur at this location on this date (the sun stays up or down all day)", event)
+	}
+	return t, nil
+}
+
+// getSunTimes returns the sun events for a given location and date.
+// By default they are computed locally; source "api" queries api.sunrise-sunset.org instead.
+func getSunTimes(lat, lng float64, date time.Time, source string) (*SunTimes, error) {
+	switch source {
+	case sunSourceNOAA, "":
+		return calculateSunTimes(lat, lng, date), nil
+	case sunSourceAPI:
+		return fetchSunTimes(lat, lng, date)
+	default:
+		return nil, fmt.Errorf("unknown sun source: %s (expected '%s' or '%s')", source, sunSourceNOAA, sunSourceAPI)
+	}
+}
+
+// calculateSunTimes computes the sun events offline with the NOAA solar position algorithm
+func calculateSunTimes(lat, lng float64, date time.Time) *SunTimes {
+	times := solar.Calculate(lat, lng, date)
+
+	return &SunTimes{
+		AstronomicalDawn: localOrZero(times.AstronomicalDawn),
+		NauticalDawn:     localOrZero(times.NauticalDawn),
+		CivilDawn:        localOrZero(times.CivilDawn),
+		Sunrise:          localOrZero(times.Sunrise),
+		SolarNoon:        localOrZero(times.SolarNoon),
+		Sunset:           localOrZero(times.Sunset),
+		CivilDusk:        localOrZero(times.CivilDusk),
+		NauticalDusk:     localOrZero(times.NauticalDusk),
+		AstronomicalDusk: localOrZero(times.AstronomicalDusk),
+	}
+}
+
+// fetchSunTimes fetches the sun events from api.sunrise-sunset.org
+func fetchSunTimes(lat, lng float64, date time.Time) (*SunTimes, error) {
 	dateStr := date.Format("2006-01-02")
 	apiURL := fmt.Sprintf("https://api.sunrise-sunset.org/json?lat=%f&lng=%f&date=%s&formatted=0", lat, lng, dateStr)
 
@@ -125,18 +368,41 @@ func getSunTimes(lat, lng float64, date time.Time) (*SunTimes, error) {
 		return nil, fmt.Errorf("API returned status: %s", sunResp.Status)
 	}
 
-	sunriseUTC, err := time.Parse(time.RFC3339, sunResp.Results.Sunrise)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse sunrise time: %v", err)
+	r := sunResp.Results
+	sunTimes := &SunTimes{}
+	fields := []struct {
+		event string
+		value string
+		dest  *time.Time
+	}{
+		{sunEventAstronomicalDawn, r.AstronomicalTwilightBegin, &sunTimes.AstronomicalDawn},
+		{sunEventNauticalDawn, r.NauticalTwilightBegin, &sunTimes.NauticalDawn},
+		{sunEventCivilDawn, r.CivilTwilightBegin, &sunTimes.CivilDawn},
+		{sunEventSunrise, r.Sunrise, &sunTimes.Sunrise},
+		{sunEventSolarNoon, r.SolarNoon, &sunTimes.SolarNoon},
+		{sunEventSunset, r.Sunset, &sunTimes.Sunset},
+		{sunEventCivilDusk, r.CivilTwilightEnd, &sunTimes.CivilDusk},
+		{sunEventNauticalDusk, r.NauticalTwilightEnd, &sunTimes.NauticalDusk},
+		{sunEventAstronomicalDusk, r.AstronomicalTwilightEnd, &sunTimes.AstronomicalDusk},
 	}
 
-	sunsetUTC, err := time.Parse(time.RFC3339, sunResp.Results.Sunset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse sunset time: %v", err)
+	for _, f := range fields {
+		t, err := time.Parse(time.RFC3339, f.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s time: %v", f.event, err)
+		}
+		// The API reports events that don't occur as 1970-01-01T00:00:01+00:00
+		if t.Year() > 1970 {
+			*f.dest = t.Local()
+		}
 	}
 
-	return &SunTimes{
-		Sunrise: sunriseUTC.Local(),
-		Sunset:  sunsetUTC.Local(),
-	}, nil
+	return sunTimes, nil
+}
+
+func localOrZero(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Local()
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveScheduleTimePolar(t *testing.T) {
+	// Tromsø has midnight sun at the June solstice: no sunrise or sunset
+	date := time.Date(2024, 6, 21, 0, 0, 0, 0, time.Local)
+	sunTimes := calculateSunTimes(69.6492, 18.9553, date)
+
+	for _, event := range []string{"SUNRISE", "SUNSET"} {
+		got, err := resolveScheduleTime(event, -30, sunTimes)
+		if err == nil {
+			t.Fatalf("resolveScheduleTime(%s) = %v, want an error", event, got)
+		}
+		if !strings.Contains(err.Error(), "does not occur") {
+			t.Errorf("resolveScheduleTime(%s) error = %q, want it to say the event doesn't occur", event, err)
+		}
+	}
+
+	if _, err := resolveScheduleTime("SOLAR_NOON", 0, sunTimes); err != nil {
+		t.Errorf("resolveScheduleTime(SOLAR_NOON) error = %v, want none", err)
+	}
+	if _, err := resolveScheduleTime("SUNRISE", 0, nil); err == nil {
+		t.Error("resolveScheduleTime without sun times succeeded, want an error")
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		value    string
+		lat, lng float64
+		ok       bool
+	}{
+		{"35.7973,-82.6840", 35.7973, -82.6840, true},
+		{" 69.6492 , 18.9553 ", 69.6492, 18.9553, true},
+		{"-33.8688,151.2093", -33.8688, 151.2093, true},
+		{"Marshall, NC", 0, 0, false},
+		{"35.7973", 0, 0, false},
+		{"91,0", 0, 0, false},
+		{"0,181", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		lat, lng, ok := parseCoordinates(tt.value)
+		if ok != tt.ok || lat != tt.lat || lng != tt.lng {
+			t.Errorf("parseCoordinates(%q) = %v, %v, %v, want %v, %v, %v", tt.value, lat, lng, ok, tt.lat, tt.lng, tt.ok)
+		}
+	}
+}
+
+// useProfile makes p the active profile for the rest of the test
+func useProfile(t *testing.T, p *Profile) {
+	t.Helper()
+	previous := activeProfile
+	activeProfile = p
+	t.Cleanup(func() { activeProfile = previous })
+}
+
+func TestGetLocationOffline(t *testing.T) {
+	useProfile(t, testProfile(t, map[string]string{
+		locationsFile: `{"marshall, nc": {"lat": 35.7973, "lng": -82.684, "name": "Marshall, NC"}}`,
+	}))
+
+	// Neither a cached city nor coordinates need the network
+	lat, lng, name, err := getLocation("Marshall, NC")
+	if err != nil || lat != 35.7973 || lng != -82.684 || name != "Marshall, NC" {
+		t.Errorf("cached city = %v, %v, %q, %v", lat, lng, name, err)
+	}
+	lat, lng, name, err = getLocation("35.7973,-82.684")
+	if err != nil || lat != 35.7973 || lng != -82.684 || name != "35.7973,-82.684" {
+		t.Errorf("coordinates = %v, %v, %q, %v", lat, lng, name, err)
+	}
+}
+
+func TestSaveLocation(t *testing.T) {
+	p := testProfile(t, nil)
+	useProfile(t, p)
+
+	saveLocation(loadLocationCache(), "marshall, nc", cachedLocation{Lat: 35.7973, Lng: -82.684, Name: "Marshall, NC"})
+	saveLocation(loadLocationCache(), ipLocationKey, cachedLocation{Lat: 34.1083, Lng: -117.2898, Name: "San Bernardino, California"})
+
+	cache := loadLocationCache()
+	if len(cache) != 2 || cache["marshall, nc"].Lat != 35.7973 || cache[ipLocationKey].Name != "San Bernardino, California" {
+		t.Errorf("cache = %+v", cache)
+	}
+
+	// A broken cache is ignored rather than failing the lookup
+	if err := os.WriteFile(filepath.Join(p.Dir, locationsFile), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cache := loadLocationCache(); len(cache) != 0 {
+		t.Errorf("broken cache = %+v, want empty", cache)
+	}
+}
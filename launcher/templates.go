@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"launcher/internal/wx"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// maxTitleLength is YouTube's limit for broadcast titles
+const maxTitleLength = 100
+
+// broadcastText is the data available to --title, --description and title_template,
+// e.g. --title 'Marshall WX {{date "Jan 2" .Date}} (sunrise {{clock .Sunrise}})'
+type broadcastText struct {
+	// Date is the planned start, Start and End the stream window
+	Date  time.Time
+	Start time.Time
+	End   time.Time
+	// Sunrise, Sunset and DayLength are for Location on the stream's day
+	Sunrise   time.Time
+	Sunset    time.Time
+	DayLength time.Duration
+	Location  string
+	// Wind is the station's latest reading when the text is rendered, or nil when the
+	// station can't be read; use {{with .Wind}}...{{end}}
+	Wind *wx.Record
+}
+
+var templateFuncs = template.FuncMap{
+	// date formats a time with a Go layout, e.g. {{date "Monday, Jan 2" .Date}}
+	"date": func(layout string, t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	},
+	// clock formats a time as HH:MM
+	"clock": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("15:04")
+	},
+	// hours formats a duration as e.g. "13h 42m"
+	"hours": func(d time.Duration) string {
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+	},
+	// cardinal converts degrees to a 16-point compass direction, e.g. {{cardinal 225}} is SW
+	"cardinal": wx.Cardinal,
+}
+
+func newBroadcastText(plan *streamPlan) broadcastText {
+	text := broadcastText{Date: plan.Start, Start: plan.Start, End: plan.End, Location: plan.Location}
+	if plan.SunTimes != nil {
+		text.Sunrise = plan.SunTimes.Sunrise
+		text.Sunset = plan.SunTimes.Sunset
+		if !text.Sunrise.IsZero() && !text.Sunset.IsZero() {
+			text.DayLength = text.Sunset.Sub(text.Sunrise)
+		}
+	}
+	return text
+}
+
+// addWind sets Wind to the station's latest reading if one of texts mentions it
+func (t *broadcastText) addWind(texts ...string) {
+	for _, s := range texts {
+		if isTemplate(s) && strings.Contains(s, ".Wind") {
+			rec, err := stationClient().Latest(context.Background())
+			if err != nil {
+				fmt.Printf("Warning: Could not read the weather station: %v\n", err)
+				return
+			}
+			t.Wind = &rec
+			return
+		}
+	}
+}
+
+// isTemplate reports whether s uses template actions
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// renderText executes s as a text/template. Text without actions is returned unchanged.
+func renderText(name, s string, data broadcastText) (string, error) {
+	if !isTemplate(s) {
+		return s, nil
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %v", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %v", name, err)
+	}
+	return b.String(), nil
+}
+
+// renderBroadcastText returns the broadcast's title and description. An empty title uses
+// the profile's title template.
+func renderBroadcastText(title, description string, data broadcastText) (string, string, error) {
+	data.addWind(title, description, activeProfile.titleTemplate())
+
+	var err error
+	if title == "" {
+		title, err = activeProfile.broadcastTitle(data)
+	} else {
+		title, err = renderText("title", title, data)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", "", fmt.Errorf("the title is empty")
+	}
+	if n := utf8.RuneCountInString(title); n > maxTitleLength {
+		return "", "", fmt.Errorf("the title is %d characters; YouTube allows %d", n, maxTitleLength)
+	}
+
+	description, err = renderText("description", description, data)
+	if err != nil {
+		return "", "", err
+	}
+	return title, description, nil
+}
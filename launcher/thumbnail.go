@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"launcher/internal/thumbnail"
+	"os"
+)
+
+// thumbnailNone as the thumbnail turns thumbnails off
+const thumbnailNone = "none"
+
+// setThumbnail uploads the image at path as a broadcast's thumbnail, or renders one from
+// the plan if path is empty. The broadcast works without one, so failures are only reported.
+func setThumbnail(scheduler *StreamScheduler, broadcastID, path, title string, plan *streamPlan) {
+	if path == thumbnailNone {
+		return
+	}
+
+	var data []byte
+	var err error
+	if path != "" {
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = renderThumbnail(title, plan)
+	}
+	if err == nil {
+		err = scheduler.SetThumbnail(broadcastID, bytes.NewReader(data))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not set the thumbnail: %v\n", err)
+		return
+	}
+	if path != "" {
+		fmt.Printf("Thumbnail set from %s\n", path)
+	} else {
+		fmt.Println("Thumbnail rendered and set")
+	}
+}
+
+func renderThumbnail(title string, plan *streamPlan) ([]byte, error) {
+	card := thumbnail.Card{Title: title, Date: plan.Start, Location: plan.Location}
+	if plan.SunTimes != nil {
+		card.Sunrise = plan.SunTimes.Sunrise
+		card.Sunset = plan.SunTimes.Sunset
+	}
+	var buf bytes.Buffer
+	if err := thumbnail.WritePNG(&buf, card); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
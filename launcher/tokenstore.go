@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/auth"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Token store backends, selected with the token_store setting
+const (
+	tokenStoreFile      = "file"
+	tokenStoreEncrypted = "encrypted"
+	tokenStoreKeyring   = "keyring"
+
+	encryptedTokenFile = "youtube_token.enc"
+	keyringService     = "obs-launcher"
+	tokenPassphraseEnv = "LAUNCHER_TOKEN_PASSPHRASE"
+)
+
+var tokenStores = []string{tokenStoreFile, tokenStoreEncrypted, tokenStoreKeyring}
+
+func validTokenStore(kind string) bool {
+	for _, k := range tokenStores {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenStore returns the profile's configured token store. It is made once per process, so
+// an encrypted store asks for its passphrase and derives its key only once.
+func (p *Profile) tokenStore() (auth.TokenStore, error) {
+	if p.store != nil {
+		return p.store, nil
+	}
+	store, err := newTokenStore(p, p.settingValue("token_store"))
+	if err != nil {
+		return nil, err
+	}
+	p.store = store
+	return store, nil
+}
+
+// configuredTokenStore returns the token store kind set in config.yaml, ignoring
+// $LAUNCHER_TOKEN_STORE, since that's where 'auth migrate' moves the token from
+func (p *Profile) configuredTokenStore() string {
+	if kind, ok := p.Config["token_store"].(string); ok && kind != "" {
+		return kind
+	}
+	s, _ := lookupSetting("token_store")
+	return s.Default
+}
+
+func newTokenStore(p *Profile, kind string) (auth.TokenStore, error) {
+	switch kind {
+	case tokenStoreFile:
+		return &auth.FileStore{Path: filepath.Join(p.Dir, tokenFile)}, nil
+	case tokenStoreEncrypted:
+		return &auth.EncryptedFileStore{Path: filepath.Join(p.Dir, encryptedTokenFile), Passphrase: readTokenPassphrase}, nil
+	case tokenStoreKeyring:
+		return &auth.KeyringStore{Service: keyringService, User: p.Name}, nil
+	default:
+		return nil, fmt.Errorf("unknown token store: %s (expected one of %s)", kind, strings.Join(tokenStores, ", "))
+	}
+}
+
+// readTokenPassphrase takes the passphrase from the environment, or prompts for it when
+// running in a terminal. Scheduled tasks have no terminal, so they need the variable.
+func readTokenPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(tokenPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("the token is encrypted; set %s to its passphrase", tokenPassphraseEnv)
+	}
+	return promptPassphrase("Token passphrase", confirm)
+}
+
+// promptPassphrase reads a passphrase from the terminal without echoing it
+func promptPassphrase(label string, confirm bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %v", err)
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %v", err)
+		}
+		if string(again) != string(passphrase) {
+			return "", errors.New("passphrases don't match")
+		}
+	}
+	return string(passphrase), nil
+}
+
+func cmdAuthMigrate(args []string) {
+	fs := flag.NewFlagSet("auth migrate", flag.ExitOnError)
+	to := fs.String("to", "", "Token store to move the token to: "+strings.Join(tokenStores, ", "))
+	fs.Usage = func() { printFlagUsage(fs, "launcher auth migrate") }
+	fs.Parse(args)
+
+	if !validTokenStore(*to) {
+		fmt.Fprintf(os.Stderr, "Error: --to must be one of %s\n", strings.Join(tokenStores, ", "))
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	from, err := newTokenStore(activeProfile, activeProfile.configuredTokenStore())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	target, err := newTokenStore(activeProfile, *to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if from.String() == target.String() {
+		fmt.Printf("Token is already stored in %s\n", from)
+		return
+	}
+
+	tok, err := from.Load()
+	if errors.Is(err, auth.ErrNoToken) {
+		fmt.Fprintf(os.Stderr, "Error: no token in %s; run 'launcher auth login' first\n", from)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading token: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := target.Save(tok); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving token: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Switch the config before deleting, so the token is never only in an unused store
+	activeProfile.Config["token_store"] = *to
+	if err := activeProfile.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving profile: %v\n", err)
+		os.Exit(1)
+	}
+	activeProfile.store = target
+	if err := from.Delete(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not delete old token: %v\n", err)
+	}
+
+	fmt.Printf("Moved token from %s to %s\n", from, target)
+	if env, ok := os.LookupEnv(settingEnv("token_store")); ok && env != *to {
+		fmt.Fprintf(os.Stderr, "Warning: $%s=%s overrides %s; unset it to use the migrated token\n", settingEnv("token_store"), env, configFile)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// videoOptions are the broadcast video's metadata, set right after it's scheduled
+type videoOptions struct {
+	CategoryID    string   `json:"category_id,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Language      string   `json:"language,omitempty"`
+	AudioLanguage string   `json:"audio_language,omitempty"`
+	// Playlist is the title of the playlist to add the video to, a Go template with the
+	// same data as --title
+	Playlist string `json:"playlist,omitempty"`
+}
+
+// videoFlags adds the video metadata options
+func videoFlags(fs *flag.FlagSet) func() videoOptions {
+	categoryID := fs.String("category-id", "", "YouTube video category ID, e.g. 28 for Science & Technology (default: the channel's)")
+	tags := fs.String("tags", "", "Comma-separated video tags")
+	language := fs.String("language", "", "Language of the title and description, e.g. 'en'")
+	audioLanguage := fs.String("audio-language", "", "Language spoken in the stream, e.g. 'en'")
+	playlist := fs.String("playlist", "", "Playlist to add the video to, created if missing; a Go template, e.g. 'Marshall WX {{date \"2006-01\" .Date}}'")
+	return func() videoOptions {
+		return videoOptions{
+			CategoryID:    *categoryID,
+			Tags:          splitList(*tags),
+			Language:      *language,
+			AudioLanguage: *audioLanguage,
+			Playlist:      *playlist,
+		}
+	}
+}
+
+func (o videoOptions) details() VideoDetails {
+	return VideoDetails{CategoryID: o.CategoryID, Tags: o.Tags, Language: o.Language, AudioLanguage: o.AudioLanguage}
+}
+
+// setVideoOptions applies the metadata to a scheduled broadcast's video and adds it to
+// playlist, the rendered playlist title. The broadcast works without them, so failures are
+// only reported.
+func setVideoOptions(scheduler *StreamScheduler, broadcastID string, opts videoOptions, playlist, privacy string) {
+	if opts.CategoryID != "" || len(opts.Tags) > 0 || opts.Language != "" || opts.AudioLanguage != "" {
+		if err := scheduler.SetVideoDetails(broadcastID, opts.details()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not set the video's category, tags or languages: %v\n", err)
+		}
+	}
+	if playlist != "" {
+		if err := scheduler.AddToPlaylist(broadcastID, playlist, privacy); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not add the video to playlist '%s': %v\n", playlist, err)
+		}
+	}
+}
+
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
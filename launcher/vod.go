@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/state"
+	"launcher/internal/wx"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// YouTube only shows chapters if there are at least this many, each at least this long
+const (
+	minChapters      = 3
+	minChapterLength = 10 * time.Second
+)
+
+// chaptersHeading starts the chapters' block in a video description
+const chaptersHeading = "Chapters"
+
+// Blocks the launcher adds to a video description, see setDescriptionBlock
+const (
+	chaptersBlock = "chapters"
+	summaryBlock  = "weather summary"
+)
+
+// vodOptions control what is added to a broadcast's video once it has ended
+type vodOptions struct {
+	Summary    bool
+	Chapters   bool
+	Thresholds wx.Thresholds
+}
+
+// vodFlags adds the options for finishing a broadcast's video
+func vodFlags(fs *flag.FlagSet) func() (vodOptions, error) {
+	summary := fs.Bool("wx-summary", true, "Add the day's weather summary to the video description")
+	chapters := fs.Bool("chapters", true, "Add chapters from the wind conditions to the video description")
+	calm := fs.Float64("chapter-calm", 5, "Sustained wind in mph below which a chapter is 'Calm'")
+	gusty := fs.Float64("chapter-gust", 20, "Gust in mph at or above which a chapter is 'Gusty'")
+	minLength := fs.Duration("chapter-min-length", 15*time.Minute, "Shortest chapter; shorter changes in the wind are merged into the chapter before")
+	return func() (vodOptions, error) {
+		if *minLength < minChapterLength {
+			return vodOptions{}, fmt.Errorf("--chapter-min-length must be at least %s", minChapterLength)
+		}
+		if *calm > *gusty {
+			return vodOptions{}, errors.New("--chapter-calm must not be above --chapter-gust")
+		}
+		return vodOptions{
+			Summary:    *summary,
+			Chapters:   *chapters,
+			Thresholds: wx.Thresholds{Calm: *calm, Gusty: *gusty, MinLength: *minLength},
+		}, nil
+	}
+}
+
+// finishVOD updates an ended broadcast's video. The stream is over by then, so failures
+// are only reported.
+func finishVOD(scheduler *StreamScheduler, baseDir, broadcastID string, opts vodOptions) {
+	if !opts.Summary && !opts.Chapters {
+		return
+	}
+
+	err := scheduler.UpdateDescription(broadcastID, func(video *youtube.Video) (string, error) {
+		start, end := broadcastWindow(baseDir, broadcastID, video)
+		day, err := readStationDay(start)
+		if err != nil {
+			return "", err
+		}
+
+		description := video.Snippet.Description
+		if opts.Chapters {
+			segs := wx.Segments(day.Records, start, end, opts.Thresholds)
+			if len(segs) >= minChapters {
+				description = setDescriptionBlock(description, chaptersBlock, chaptersText(segs, start, opts.Thresholds))
+				fmt.Printf("Added %d chapters to the video description\n", len(segs))
+			} else {
+				fmt.Printf("The wind changed too little for chapters (YouTube needs at least %d)\n", minChapters)
+			}
+		}
+		if opts.Summary {
+			if summary := wx.Summarize(day.Records, start, end); summary != nil {
+				description = setDescriptionBlock(description, summaryBlock, summary.Text())
+				fmt.Println("Added the weather summary to the video description")
+			} else {
+				fmt.Println("No weather station readings during the stream; no summary added")
+			}
+		}
+		return description, nil
+	})
+	if errors.Is(err, wx.ErrNoData) {
+		err = errors.New("the weather station has no data for the stream's day")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not update the video description: %v\n", err)
+	}
+}
+
+// readStationDay reads the station's file for t's day. The error wraps wx.ErrNoData if
+// the station has none.
+func readStationDay(t time.Time) (*wx.Day, error) {
+	day, err := stationClient().Day(context.Background(), t)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the weather station: %w", err)
+	}
+	return day, nil
+}
+
+// broadcastWindow returns when a broadcast was live: YouTube's actual start and end times,
+// else the recorded transitions, else the planned times
+func broadcastWindow(baseDir, broadcastID string, video *youtube.Video) (time.Time, time.Time) {
+	var start, end time.Time
+	if d := video.LiveStreamingDetails; d != nil {
+		start, _ = time.Parse(time.RFC3339, d.ActualStartTime)
+		end, _ = time.Parse(time.RFC3339, d.ActualEndTime)
+	}
+
+	openStateStore(baseDir).View(func(st *state.State) error {
+		b := st.Find(broadcastID)
+		if b == nil {
+			return nil
+		}
+		if start.IsZero() {
+			start = b.TransitionTime(state.StatusLive)
+		}
+		if start.IsZero() {
+			start = b.PlannedStart
+		}
+		if end.IsZero() {
+			end = b.TransitionTime(state.StatusComplete)
+		}
+		return nil
+	})
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.IsZero() {
+		start = end.Add(-24 * time.Hour)
+	}
+	return start.Local(), end.Local()
+}
+
+// chaptersText lists the segments as YouTube chapter timestamps, relative to the
+// broadcast's start. The first is always 0:00.
+func chaptersText(segs []wx.Segment, start time.Time, t wx.Thresholds) string {
+	lines := []string{chaptersHeading}
+	for _, seg := range segs {
+		lines = append(lines, chapterTimestamp(seg.Start.Sub(start))+" "+seg.Title(t))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// chapterTimestamp formats an offset as M:SS, or H:MM:SS from an hour
+func chapterTimestamp(d time.Duration) string {
+	secs := int(d / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// descriptionMarker is the first line of a block the launcher adds to a video description.
+// Only paragraphs starting with it are ever replaced, never text the user wrote.
+func descriptionMarker(name string) string {
+	return "[obs-launcher: " + name + "]"
+}
+
+// setDescriptionBlock replaces the launcher's block called name in description, or appends
+// block as a new paragraph, so finishing a video twice doesn't repeat it
+func setDescriptionBlock(description, name, block string) string {
+	marker := descriptionMarker(name)
+	block = marker + "\n" + block
+	description = strings.TrimRight(description, "\n ")
+	if description == "" {
+		return block
+	}
+	paragraphs := strings.Split(description, "\n\n")
+	for i, p := range paragraphs {
+		if first, _, _ := strings.Cut(p, "\n"); strings.TrimSpace(first) == marker {
+			paragraphs[i] = block
+			return strings.Join(paragraphs, "\n\n")
+		}
+	}
+	return description + "\n\n" + block
+}
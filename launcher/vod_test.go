@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"launcher/internal/wx"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetDescriptionBlock(t *testing.T) {
+	chapters := "Chapters\n0:00 Calm\n1:42:00 Thermals building"
+	block := "[obs-launcher: chapters]\n" + chapters
+
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{name: "empty", description: "", want: block},
+		{name: "appended", description: "Live from North Ridge.\n", want: "Live from North Ridge.\n\n" + block},
+		{
+			name:        "replaced",
+			description: "Live from North Ridge.\n\n[obs-launcher: chapters]\nChapters\n0:00 Gusty 20+\n\nThanks for watching!",
+			want:        "Live from North Ridge.\n\n" + block + "\n\nThanks for watching!",
+		},
+		{
+			name:        "user paragraph with the same heading",
+			description: "Chapters of the trip so far:\n0:00 Launch\n\nLive from North Ridge.",
+			want:        "Chapters of the trip so far:\n0:00 Launch\n\nLive from North Ridge.\n\n" + block,
+		},
+		{
+			name:        "other block left alone",
+			description: "[obs-launcher: weather summary]\nWeather summary (06:30-17:28, 659 readings)",
+			want:        "[obs-launcher: weather summary]\nWeather summary (06:30-17:28, 659 readings)\n\n" + block,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setDescriptionBlock(tt.description, chaptersBlock, chapters); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// Finishing the same video twice doesn't add a second block
+	once := setDescriptionBlock("Live from North Ridge.", chaptersBlock, chapters)
+	if twice := setDescriptionBlock(once, chaptersBlock, chapters); twice != once {
+		t.Errorf("second pass changed the description:\n%s", twice)
+	}
+}
+
+func TestChaptersText(t *testing.T) {
+	start := time.Date(2025, 11, 27, 6, 30, 0, 0, time.UTC)
+	thresholds := wx.Thresholds{Calm: 5, Gusty: 20}
+	segs := []wx.Segment{
+		{Start: start, Condition: wx.ConditionCalm},
+		{Start: start.Add(42 * time.Minute), Condition: wx.ConditionThermals},
+		{Start: start.Add(6*time.Hour + 30*time.Minute + 5*time.Second), Condition: wx.ConditionGusty, Direction: "W"},
+	}
+	want := "Chapters\n0:00 Calm\n42:00 Thermals building\n6:30:05 Gusty W 20+"
+	if got := chaptersText(segs, start, thresholds); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReadStationDay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wx20251127.dat" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("13:10,11/27/2025,16,26,276\r\n13:15,11/27/2025,15,24,273\r\n"))
+	}))
+	defer server.Close()
+	useProfile(t, testProfile(t, map[string]string{configFile: "station_url: " + server.URL + "/wx\n"}))
+
+	day, err := readStationDay(time.Date(2025, 11, 27, 12, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(day.Records) != 2 || day.Records[1].Gust != 24 {
+		t.Errorf("records = %+v", day.Records)
+	}
+
+	// finishVOD reports a day without a file in its own words
+	_, err = readStationDay(time.Date(2025, 11, 28, 12, 0, 0, 0, time.Local))
+	if !errors.Is(err, wx.ErrNoData) {
+		t.Errorf("error = %v, want wx.ErrNoData in the chain", err)
+	}
+}
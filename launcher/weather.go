@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"launcher/internal/atomicfile"
+	"launcher/internal/wx"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+func printWeatherUsage() {
+	fmt.Println("Weather station commands")
+	fmt.Println()
+	fmt.Println("Usage: launcher weather <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  now    Show the station's latest reading")
+	fmt.Println("  day    Show every reading of a day")
+	fmt.Println("  watch  Keep text files for OBS text sources up to date")
+	fmt.Println()
+	fmt.Println("Run 'launcher weather <command> --help' for more information.")
+}
+
+// cmdWeather handles the weather subcommand
+func cmdWeather(args []string) {
+	if len(args) < 1 {
+		printWeatherUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "now":
+		cmdWeatherNow(args[1:])
+	case "day":
+		cmdWeatherDay(args[1:])
+	case "watch":
+		cmdWeatherWatch(args[1:])
+	case "-help", "--help", "help":
+		printWeatherUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown weather command: %s\n\n", args[0])
+		printWeatherUsage()
+		os.Exit(1)
+	}
+}
+
+// stationFlags adds the options that locate the weather station's files
+func stationFlags(fs *flag.FlagSet) func() *wx.Client {
+	baseURL := fs.String("station-url", wx.DefaultBaseURL, "Weather station files URL, before the YYYYMMDD date")
+	suffix := fs.String("station-suffix", wx.DefaultSuffix, "Weather station files suffix, after the date")
+	return func() *wx.Client {
+		return &wx.Client{BaseURL: *baseURL, Suffix: *suffix}
+	}
+}
+
+// stationClient returns the client for the active profile's station settings, for commands
+// without the station options
+func stationClient() *wx.Client {
+	return &wx.Client{BaseURL: activeProfile.settingValue("station_url"), Suffix: activeProfile.settingValue("station_suffix")}
+}
+
+func cmdWeatherNow(args []string) {
+	fs := flag.NewFlagSet("weather now", flag.ExitOnError)
+	client := stationFlags(fs)
+	format := fs.String("format", "human", "Output format: 'human' or 'json'")
+	fs.Usage = func() { printFlagUsage(fs, "launcher weather now") }
+	parseFlags(fs, args)
+
+	rec, err := client().Latest(context.Background())
+	if errors.Is(err, wx.ErrNoData) {
+		fmt.Fprintln(os.Stderr, "Error: the station has no readings for today yet")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting station data: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		printJSON(rec)
+	default:
+		fmt.Printf("Time:       %s (%s ago)\n", rec.Time.Format("2006-01-02 15:04"), time.Since(rec.Time).Round(time.Minute))
+		fmt.Printf("Wind:       %.1f mph\n", rec.Wind)
+		fmt.Printf("Gust:       %.1f mph\n", rec.Gust)
+		fmt.Printf("Direction:  %s (%.0f°)\n", rec.Cardinal(), rec.Direction)
+	}
+}
+
+func cmdWeatherDay(args []string) {
+	fs := flag.NewFlagSet("weather day", flag.ExitOnError)
+	client := stationFlags(fs)
+	dateFlag := fs.String("date", "", "Day to show, YYYY-MM-DD (default: today)")
+	format := fs.String("format", "human", "Output format: 'human' or 'json'")
+	fs.Usage = func() { printFlagUsage(fs, "launcher weather day") }
+	parseFlags(fs, args)
+
+	date := time.Now()
+	if *dateFlag != "" {
+		var err error
+		if date, err = time.ParseInLocation("2006-01-02", *dateFlag, time.Local); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --date '%s' (expected YYYY-MM-DD)\n", *dateFlag)
+			os.Exit(1)
+		}
+	}
+
+	day, err := client().Day(context.Background(), date)
+	if errors.Is(err, wx.ErrNoData) {
+		fmt.Fprintf(os.Stderr, "Error: the station has no data for %s\n", date.Format("2006-01-02"))
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting station data: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		printJSON(day.Records)
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tWIND\tGUST\tDIRECTION")
+		for _, rec := range day.Records {
+			fmt.Fprintf(w, "%s\t%.1f\t%.1f\t%s (%.0f°)\n", rec.Time.Format("15:04"), rec.Wind, rec.Gust, rec.Cardinal(), rec.Direction)
+		}
+		w.Flush()
+	}
+	for _, e := range day.Malformed {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %v: %q\n", e, e.Text)
+	}
+}
+
+// Files written by 'weather watch'
+const (
+	windTextFile     = "wind.txt"
+	dateTimeTextFile = "datetime.txt"
+	weatherJSONFile  = "weather.json"
+)
+
+// weatherSnapshot is the combined JSON file written by 'weather watch'
+type weatherSnapshot struct {
+	wx.Text
+	Online bool `json:"online"`
+	// Reading is the latest line, when it could be parsed
+	Reading *wx.Record `json:"reading,omitempty"`
+	URL     string     `json:"url"`
+	Error   string     `json:"error,omitempty"`
+	Updated time.Time  `json:"updated"`
+}
+
+// readWeatherSnapshot fetches today's file and formats it like weather_data.lua
+func readWeatherSnapshot(ctx context.Context, client *wx.Client, hours wx.Window, now time.Time) *weatherSnapshot {
+	snap := &weatherSnapshot{URL: client.URL(now), Updated: now}
+	data, err := client.Fetch(ctx, now)
+	if err == nil && len(data) == 0 {
+		err = wx.ErrNoData
+	}
+	if err != nil {
+		snap.Text = wx.ErrorText(snap.URL)
+		snap.Error = err.Error()
+		return snap
+	}
+
+	fields := wx.LastFields(string(data))
+	snap.Text = wx.FormatText(fields, now, hours)
+	snap.Online = hours.Contains(now)
+	if rec, err := wx.ParseRecord(strings.Join(fields, ","), client.Location); err == nil {
+		snap.Reading = &rec
+	}
+	return snap
+}
+
+// writeWeatherSnapshot replaces the text and JSON files atomically, so OBS never reads a
+// half-written one.
+func writeWeatherSnapshot(dir string, snap *weatherSnapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{windTextFile, []byte(snap.Wind)},
+		{dateTimeTextFile, []byte(snap.DateTime)},
+		{weatherJSONFile, data},
+	}
+	for _, f := range files {
+		if err := atomicfile.Write(filepath.Join(dir, f.name), f.data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cmdWeatherWatch(args []string) {
+	fs := flag.NewFlagSet("weather watch", flag.ExitOnError)
+	client := stationFlags(fs)
+	hoursFlag := stationHoursFlags(fs)
+	interval := fs.Duration("station-interval", time.Minute, "How often to read the weather station")
+	outDir := fs.String("out-dir", "", "Directory to write "+windTextFile+", "+dateTimeTextFile+" and "+weatherJSONFile+" to (required)")
+	once := fs.Bool("once", false, "Write the files once and exit")
+	fs.Usage = func() { printFlagUsage(fs, "launcher weather watch") }
+	parseFlags(fs, args)
+
+	if *outDir == "" {
+		fmt.Fprintln(os.Stderr, "Error: --out-dir is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+	hours, err := hoursFlag()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *interval < time.Second {
+		fmt.Fprintln(os.Stderr, "Error: --station-interval must be at least 1s")
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	station := client()
+	if !*once {
+		log.Printf("Writing station data to %s every %s", *outDir, *interval)
+	}
+	for {
+		snap := readWeatherSnapshot(ctx, station, hours, time.Now())
+		if snap.Error != "" {
+			log.Printf("Could not read the weather station: %s", snap.Error)
+		}
+		// OBS may briefly hold a file open on Windows; the next update retries
+		if err := writeWeatherSnapshot(*outDir, snap); err != nil {
+			log.Printf("Could not write station data: %v", err)
+		}
+		if *once {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Stopped watching the weather station")
+			return
+		case <-time.After(*interval):
+		}
+	}
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
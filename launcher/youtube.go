@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"launcher/internal/auth"
 	"log"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -17,103 +19,142 @@ import (
 )
 
 const (
-	credentialsFile = "credentials.json"
-	tokenFile       = "youtube_token.json"
-	youtubeStreamTitle    = "Marshall Weather Station - Stream" // This differs from the Broadcast title!
+	credentialsFile    = "credentials.json"
+	tokenFile          = "youtube_token.json"
+	youtubeStreamTitle = "Marshall Weather Station - Stream" // This differs from the Broadcast title!
 )
 
 type StreamScheduler struct {
-	service     *youtube.Service
+	service        *youtube.Service
 	credentialsDir string
+	streamTitle    string
+	onTransition   func(broadcastID, status string)
 }
 
-func getClient(config *oauth2.Config, credentialsDir string) (*http.Client, error) {
-	tokFile := filepath.Join(credentialsDir, tokenFile)
-	tok, err := tokenFromFile(tokFile)
+// errNotLoggedIn is returned instead of prompting when no token has been saved, so
+// scheduled tasks fail fast rather than wait for input
+var errNotLoggedIn = errors.New("not signed in to YouTube; run 'launcher auth login' first")
+
+// readCredentials returns the OAuth client (credentials.json) from credentialsDir, or from
+// next to the executable, and the path it was read from
+func readCredentials(credentialsDir string) ([]byte, string, error) {
+	credPath := filepath.Join(credentialsDir, credentialsFile)
+	b, err := os.ReadFile(credPath)
+	if errors.Is(err, os.ErrNotExist) && credentialsDir != executableDir() {
+		// Profiles can share the OAuth client next to the executable
+		credPath = filepath.Join(executableDir(), credentialsFile)
+		b, err = os.ReadFile(credPath)
+	}
 	if err != nil {
-		tok, err = getTokenFromWeb(config)
-		if err != nil {
-			return nil, err
-		}
-		saveToken(tokFile, tok)
+		return nil, credPath, fmt.Errorf("unable to read credentials file (%s): %v\nPlease ensure credentials.json exists", credPath, err)
 	}
-	return config.Client(context.Background(), tok), nil
+	return b, credPath, nil
 }
 
-func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-
-	fmt.Println()
-	fmt.Println("================================================================================")
-	fmt.Println("AUTHORIZATION REQUIRED")
-	fmt.Println("================================================================================")
-	fmt.Println("Step 1: Visit this URL in your browser:")
-	fmt.Printf("\n%s\n\n", authURL)
-	fmt.Println("Step 2: After authorizing, Google will display an authorization code.")
-	fmt.Println("Step 3: Copy the code and paste it below.")
-	fmt.Println()
-	fmt.Print("Enter authorization code: ")
-
-	var authCode string
-	fmt.Scanln(&authCode)
-
-	tok, err := config.Exchange(context.Background(), authCode)
+// loadOAuthConfig reads the OAuth client from credentials.json
+func loadOAuthConfig(credentialsDir string) (*oauth2.Config, error) {
+	b, _, err := readCredentials(credentialsDir)
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve token: %v", err)
+		return nil, err
 	}
 
-	fmt.Println("\nAuthentication successful!")
-	return tok, nil
+	config, err := google.ConfigFromJSON(b, youtube.YoutubeScope)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse credentials file: %v", err)
+	}
+	return config, nil
 }
 
-func tokenFromFile(file string) (*oauth2.Token, error) {
-	f, err := os.Open(file)
+// getTokenSource returns a token source for the profile's saved token. Refreshed tokens are
+// written back so a rotated refresh token isn't lost.
+func getTokenSource(config *oauth2.Config, profile *Profile) (oauth2.TokenSource, error) {
+	store, err := profile.tokenStore()
+	if err != nil {
+		return nil, err
+	}
+	tok, err := store.Load()
+	if errors.Is(err, auth.ErrNoToken) {
+		return nil, errNotLoggedIn
+	}
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	return tok, err
+
+	return auth.PersistingTokenSource(context.Background(), config, tok, store, func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}), nil
 }
 
-func saveToken(path string, token *oauth2.Token) {
-	fmt.Printf("Saving credential file to: %s\n", path)
-	f, err := os.Create(path)
+// getTokenFromWeb runs the interactive authorization flow. With noBrowser the user pastes
+// the redirected URL instead of the browser being sent to a local listener.
+func getTokenFromWeb(config *oauth2.Config, noBrowser bool) (*oauth2.Token, error) {
+	fmt.Println()
+	fmt.Println("================================================================================")
+	fmt.Println("AUTHORIZATION REQUIRED")
+	fmt.Println("================================================================================")
+
+	flow := &auth.Flow{Config: config}
+	var tok *oauth2.Token
+	var err error
+	if noBrowser {
+		tok, err = flow.Manual(context.Background())
+	} else {
+		tok, err = flow.Loopback(context.Background())
+	}
 	if err != nil {
-		log.Fatalf("Unable to cache oauth token: %v", err)
+		return nil, err
 	}
-	defer f.Close()
-	json.NewEncoder(f).Encode(token)
+
+	fmt.Println("\nAuthentication successful!")
+	return tok, nil
 }
 
-func NewStreamScheduler(credentialsDir string) (*StreamScheduler, error) {
+func NewStreamScheduler(profile *Profile) (*StreamScheduler, error) {
 	ctx := context.Background()
 
-	credPath := filepath.Join(credentialsDir, credentialsFile)
-	b, err := os.ReadFile(credPath)
+	config, err := loadOAuthConfig(profile.Dir)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read credentials file (%s): %v\nPlease ensure credentials.json exists", credPath, err)
+		return nil, err
 	}
 
-	config, err := google.ConfigFromJSON(b, youtube.YoutubeScope)
+	src, err := getTokenSource(config, profile)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse credentials file: %v", err)
+		return nil, err
 	}
 
-	client, err := getClient(config, credentialsDir)
+	service, err := youtube.NewService(ctx, option.WithHTTPClient(oauth2.NewClient(ctx, src)))
 	if err != nil {
-		return nil, fmt.Errorf("unable to create client: %v", err)
+		return nil, fmt.Errorf("unable to create YouTube service: %w", err)
 	}
 
-	service, err := youtube.NewService(ctx, option.WithHTTPClient(client))
+	// Goes to stderr so machine-readable output on stdout (e.g. stream status --format json) stays clean
+	fmt.Fprintln(os.Stderr, "Authorized with YouTube API")
+
+	return &StreamScheduler{service: service, credentialsDir: profile.Dir, streamTitle: profile.streamTitle()}, nil
+}
+
+// Channel returns the channel the scheduler is authorized for
+func (s *StreamScheduler) Channel() (*youtube.Channel, error) {
+	resp, err := s.service.Channels.List([]string{"snippet"}).Mine(true).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to create YouTube service: %v", err)
+		return nil, fmt.Errorf("error fetching channel: %w", err)
 	}
+	if len(resp.Items) == 0 {
+		return nil, errors.New("no YouTube channel found for this account")
+	}
+	return resp.Items[0], nil
+}
 
-	fmt.Println("Authorized with YouTube API")
+// OnTransition registers fn to be called after each successful lifecycle transition
+// (testing, live, complete) so callers can record when it happened.
+func (s *StreamScheduler) OnTransition(fn func(broadcastID, status string)) {
+	s.onTransition = fn
+}
 
-	return &StreamScheduler{service: service, credentialsDir: credentialsDir}, nil
+func (s *StreamScheduler) transitioned(broadcastID, status string) {
+	if s.onTransition != nil {
+		s.onTransition(broadcastID, status)
+	}
 }
 
 func (s *StreamScheduler) ScheduleStream(title, description string, scheduledTime time.Time, privacy string) (*youtube.LiveBroadcast, *youtube.LiveStream, error) {
@@ -141,7 +182,7 @@ func (s *StreamScheduler) ScheduleStream(title, description string, scheduledTim
 	broadcastCall := s.service.LiveBroadcasts.Insert([]string{"snippet", "contentDetails", "status"}, broadcast)
 	broadcastResponse, err := broadcastCall.Do()
 	if err != nil {
-		return nil, nil, fmt.Errorf("error creating broadcast: %v", err)
+		return nil, nil, fmt.Errorf("error creating broadcast: %w", err)
 	}
 
 	fmt.Printf("Broadcast created with ID: %s\n", broadcastResponse.Id)
@@ -149,13 +190,13 @@ func (s *StreamScheduler) ScheduleStream(title, description string, scheduledTim
 	streamListCall := s.service.LiveStreams.List([]string{"snippet", "cdn"})
 	streamListResponse, err := streamListCall.Mine(true).Do()
 	if err != nil {
-		return nil, nil, fmt.Errorf("error listing streams: %v", err)
+		return nil, nil, fmt.Errorf("error listing streams: %w", err)
 	}
 
 	var stream *youtube.LiveStream
 
 	for _, streamItem := range streamListResponse.Items {
-		if streamItem.Snippet.Title == youtubeStreamTitle {
+		if streamItem.Snippet.Title == s.streamTitle {
 			stream = streamItem
 			break
 		}
@@ -164,7 +205,7 @@ func (s *StreamScheduler) ScheduleStream(title, description string, scheduledTim
 	if stream == nil {
 		newStream := &youtube.LiveStream{
 			Snippet: &youtube.LiveStreamSnippet{
-				Title: youtubeStreamTitle,
+				Title: s.streamTitle,
 			},
 			Cdn: &youtube.CdnSettings{
 				FrameRate:     "variable",
@@ -175,7 +216,7 @@ func (s *StreamScheduler) ScheduleStream(title, description string, scheduledTim
 		streamCall := s.service.LiveStreams.Insert([]string{"snippet", "cdn"}, newStream)
 		streamResponse, err := streamCall.Do()
 		if err != nil {
-			return nil, nil, fmt.Errorf("error creating new stream: %v", err)
+			return nil, nil, fmt.Errorf("error creating new stream: %w", err)
 		}
 		stream = streamResponse
 	}
@@ -183,7 +224,7 @@ func (s *StreamScheduler) ScheduleStream(title, description string, scheduledTim
 	bindCall := s.service.LiveBroadcasts.Bind(broadcastResponse.Id, []string{"id", "contentDetails"}).StreamId(stream.Id)
 	_, err = bindCall.Do()
 	if err != nil {
-		return nil, nil, fmt.Errorf("error binding broadcast to stream: %v", err)
+		return nil, nil, fmt.Errorf("error binding broadcast to stream: %w", err)
 	}
 	fmt.Printf("Stream bound with ID: %s, Title: %s\n", stream.Id, stream.Snippet.Title)
 
@@ -198,23 +239,71 @@ func (s *StreamScheduler) ScheduleStream(title, description string, scheduledTim
 	return broadcastResponse, stream, nil
 }
 
-func (s *StreamScheduler) GoLive(broadcastID string) error {
+// GoLiveOptions controls how long GoLive waits for YouTube before transitioning
+type GoLiveOptions struct {
+	// Timeout bounds the whole wait for stream health and the testing state
+	Timeout time.Duration
+	// PollInterval is the delay before the first re-check; it grows by half after each poll
+	PollInterval time.Duration
+	// MaxPollInterval caps the delay between polls
+	MaxPollInterval time.Duration
+}
+
+func DefaultGoLiveOptions() GoLiveOptions {
+	return GoLiveOptions{
+		Timeout:         5 * time.Minute,
+		PollInterval:    5 * time.Second,
+		MaxPollInterval: 30 * time.Second,
+	}
+}
+
+// GoLive waits until YouTube sees healthy ingest on the broadcast's bound stream,
+// moves the broadcast to testing, waits for YouTube to confirm it, then transitions to live.
+func (s *StreamScheduler) GoLive(broadcastID string, opts GoLiveOptions) error {
 	fmt.Println("Transitioning broadcast to LIVE...")
 
-	testingCall := s.service.LiveBroadcasts.Transition("testing", broadcastID, []string{"status"})
-	_, err := testingCall.Do()
+	deadline := time.Now().Add(opts.Timeout)
+
+	broadcast, err := s.getBroadcast(broadcastID)
 	if err != nil {
-		fmt.Println("Broadcast already in testing or live mode")
-	} else {
-		fmt.Println("Broadcast in testing mode")
-		time.Sleep(2 * time.Second)
+		return err
+	}
+	if broadcast.Status.LifeCycleStatus == "live" || broadcast.Status.LifeCycleStatus == "liveStarting" {
+		fmt.Println("Broadcast is already LIVE")
+		return nil
+	}
+
+	streamID := broadcast.ContentDetails.BoundStreamId
+	if streamID == "" {
+		return fmt.Errorf("broadcast %s is not bound to a stream", broadcastID)
+	}
+
+	fmt.Printf("Waiting for YouTube to receive data on stream %s...\n", streamID)
+	if err := s.waitForStreamHealth(streamID, deadline, opts); err != nil {
+		return err
+	}
+	fmt.Println("Stream is active and healthy")
+
+	lifeCycleStatus := broadcast.Status.LifeCycleStatus
+	if lifeCycleStatus != "testing" && lifeCycleStatus != "testStarting" {
+		testingCall := s.service.LiveBroadcasts.Transition("testing", broadcastID, []string{"status"})
+		if _, err := testingCall.Do(); err != nil {
+			return fmt.Errorf("error transitioning to testing: %w", err)
+		}
+		s.transitioned(broadcastID, "testing")
+	}
+
+	if err := s.waitForLifeCycleStatus(broadcastID, "testing", deadline, opts); err != nil {
+		return err
 	}
+	fmt.Println("Broadcast in testing mode")
 
 	liveCall := s.service.LiveBroadcasts.Transition("live", broadcastID, []string{"status"})
 	_, err = liveCall.Do()
 	if err != nil {
-		return fmt.Errorf("error transitioning to live: %v", err)
+		return fmt.Errorf("error transitioning to live: %w", err)
 	}
+	s.transitioned(broadcastID, "live")
 
 	fmt.Println("Broadcast is now LIVE!")
 	fmt.Printf("  Watch at: https://youtube.com/watch?v=%s\n\n", broadcastID)
@@ -222,14 +311,363 @@ func (s *StreamScheduler) GoLive(broadcastID string) error {
 	return nil
 }
 
+func (s *StreamScheduler) getBroadcast(broadcastID string) (*youtube.LiveBroadcast, error) {
+	resp, err := s.service.LiveBroadcasts.List([]string{"status", "contentDetails"}).Id(broadcastID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching broadcast: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return nil, fmt.Errorf("broadcast not found: %s", broadcastID)
+	}
+	return resp.Items[0], nil
+}
+
+// waitForStreamHealth polls the live stream until its status is active and its health is good or ok
+func (s *StreamScheduler) waitForStreamHealth(streamID string, deadline time.Time, opts GoLiveOptions) error {
+	var last *youtube.LiveStreamStatus
+
+	err := pollUntil(deadline, opts, func() (bool, error) {
+		resp, err := s.service.LiveStreams.List([]string{"status"}).Id(streamID).Do()
+		if err != nil {
+			return false, fmt.Errorf("error fetching stream status: %w", err)
+		}
+		if len(resp.Items) == 0 {
+			return false, fmt.Errorf("stream not found: %s", streamID)
+		}
+
+		last = resp.Items[0].Status
+		health := ""
+		if last.HealthStatus != nil {
+			health = last.HealthStatus.Status
+		}
+		fmt.Printf("  Stream status: %s, health: %s\n", last.StreamStatus, health)
+
+		return last.StreamStatus == "active" && (health == "good" || health == "ok"), nil
+	})
+	if err == errPollTimeout {
+		msg := fmt.Sprintf("timed out after %s waiting for stream to become active", opts.Timeout)
+		if last != nil && last.HealthStatus != nil {
+			msg += fmt.Sprintf(" (status: %s, health: %s)", last.StreamStatus, last.HealthStatus.Status)
+			if issues := describeConfigurationIssues(last.HealthStatus.ConfigurationIssues); issues != "" {
+				msg += "\nYouTube reported these configuration issues:\n" + issues
+			}
+		}
+		return errors.New(msg)
+	}
+	return err
+}
+
+// waitForLifeCycleStatus polls the broadcast until it reaches the wanted lifeCycleStatus
+func (s *StreamScheduler) waitForLifeCycleStatus(broadcastID, want string, deadline time.Time, opts GoLiveOptions) error {
+	var last string
+
+	err := pollUntil(deadline, opts, func() (bool, error) {
+		broadcast, err := s.getBroadcast(broadcastID)
+		if err != nil {
+			return false, err
+		}
+		last = broadcast.Status.LifeCycleStatus
+		return last == want, nil
+	})
+	if err == errPollTimeout {
+		return fmt.Errorf("timed out after %s waiting for broadcast to reach %s (status: %s)", opts.Timeout, want, last)
+	}
+	return err
+}
+
+var errPollTimeout = errors.New("poll timed out")
+
+// pollUntil calls check with a growing delay until it reports done, fails, or the deadline passes
+func pollUntil(deadline time.Time, opts GoLiveOptions, check func() (bool, error)) error {
+	interval := opts.PollInterval
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return errPollTimeout
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+
+		interval += interval / 2
+		if interval > opts.MaxPollInterval {
+			interval = opts.MaxPollInterval
+		}
+	}
+}
+
+func describeConfigurationIssues(issues []*youtube.LiveStreamConfigurationIssue) string {
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, fmt.Sprintf("  [%s] %s: %s", issue.Severity, issue.Type, issue.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DeleteBroadcast deletes a broadcast, e.g. to cancel an upcoming stream
+func (s *StreamScheduler) DeleteBroadcast(broadcastID string) error {
+	if err := s.service.LiveBroadcasts.Delete(broadcastID).Do(); err != nil {
+		return fmt.Errorf("error deleting broadcast: %w", err)
+	}
+	return nil
+}
+
+// RescheduleBroadcast moves a broadcast's scheduled start and end times
+func (s *StreamScheduler) RescheduleBroadcast(broadcastID string, start, end time.Time) error {
+	resp, err := s.service.LiveBroadcasts.List([]string{"snippet"}).Id(broadcastID).Do()
+	if err != nil {
+		return fmt.Errorf("error fetching broadcast: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return fmt.Errorf("broadcast not found: %s", broadcastID)
+	}
+
+	// Updating the snippet replaces it entirely, so send back the current one with the new times
+	broadcast := resp.Items[0]
+	broadcast.Snippet.ScheduledStartTime = start.Format(time.RFC3339)
+	broadcast.Snippet.ScheduledEndTime = end.Format(time.RFC3339)
+
+	if _, err := s.service.LiveBroadcasts.Update([]string{"snippet"}, broadcast).Do(); err != nil {
+		return fmt.Errorf("error updating broadcast: %w", err)
+	}
+	return nil
+}
+
+// BroadcastReport is what YouTube currently reports for a broadcast and its bound stream
+type BroadcastReport struct {
+	BroadcastID        string        `json:"broadcast_id"`
+	Title              string        `json:"title"`
+	LifeCycleStatus    string        `json:"life_cycle_status"`
+	PrivacyStatus      string        `json:"privacy_status"`
+	ScheduledStartTime string        `json:"scheduled_start_time,omitempty"`
+	ScheduledEndTime   string        `json:"scheduled_end_time,omitempty"`
+	ActualStartTime    string        `json:"actual_start_time,omitempty"`
+	ActualEndTime      string        `json:"actual_end_time,omitempty"`
+	Stream             *StreamReport `json:"stream,omitempty"`
+}
+
+type StreamReport struct {
+	StreamID            string               `json:"stream_id"`
+	Title               string               `json:"title"`
+	StreamStatus        string               `json:"stream_status"`
+	HealthStatus        string               `json:"health_status,omitempty"`
+	ConfigurationIssues []ConfigurationIssue `json:"configuration_issues,omitempty"`
+}
+
+type ConfigurationIssue struct {
+	Type        string `json:"type"`
+	Severity    string `json:"severity"`
+	Reason      string `json:"reason"`
+	Description string `json:"description"`
+}
+
+// GetBroadcastReport fetches the broadcast's lifecycle, privacy and timing along with
+// the bound stream's ingest status and health
+func (s *StreamScheduler) GetBroadcastReport(broadcastID string) (*BroadcastReport, error) {
+	resp, err := s.service.LiveBroadcasts.List([]string{"snippet", "status", "contentDetails"}).Id(broadcastID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching broadcast: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return nil, fmt.Errorf("broadcast not found: %s", broadcastID)
+	}
+	broadcast := resp.Items[0]
+
+	report := &BroadcastReport{
+		BroadcastID:        broadcast.Id,
+		Title:              broadcast.Snippet.Title,
+		LifeCycleStatus:    broadcast.Status.LifeCycleStatus,
+		PrivacyStatus:      broadcast.Status.PrivacyStatus,
+		ScheduledStartTime: broadcast.Snippet.ScheduledStartTime,
+		ScheduledEndTime:   broadcast.Snippet.ScheduledEndTime,
+		ActualStartTime:    broadcast.Snippet.ActualStartTime,
+		ActualEndTime:      broadcast.Snippet.ActualEndTime,
+	}
+
+	streamID := broadcast.ContentDetails.BoundStreamId
+	if streamID == "" {
+		return report, nil
+	}
+
+	streamResp, err := s.service.LiveStreams.List([]string{"snippet", "status"}).Id(streamID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stream status: %w", err)
+	}
+	if len(streamResp.Items) == 0 {
+		return report, nil
+	}
+	stream := streamResp.Items[0]
+
+	report.Stream = &StreamReport{
+		StreamID:     stream.Id,
+		Title:        stream.Snippet.Title,
+		StreamStatus: stream.Status.StreamStatus,
+	}
+	if health := stream.Status.HealthStatus; health != nil {
+		report.Stream.HealthStatus = health.Status
+		for _, issue := range health.ConfigurationIssues {
+			report.Stream.ConfigurationIssues = append(report.Stream.ConfigurationIssues, ConfigurationIssue{
+				Type:        issue.Type,
+				Severity:    issue.Severity,
+				Reason:      issue.Reason,
+				Description: issue.Description,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// UpdateDescription rewrites a video's description. update is given the video, with its
+// snippet and live streaming details, and returns the new description.
+func (s *StreamScheduler) UpdateDescription(videoID string, update func(*youtube.Video) (string, error)) error {
+	resp, err := s.service.Videos.List([]string{"snippet", "liveStreamingDetails"}).Id(videoID).Do()
+	if err != nil {
+		return fmt.Errorf("error fetching video: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return fmt.Errorf("video not found: %s", videoID)
+	}
+
+	video := resp.Items[0]
+	description, err := update(video)
+	if err != nil {
+		return err
+	}
+	if description == video.Snippet.Description {
+		return nil
+	}
+
+	// Updating the snippet replaces it entirely, so send back the current one with the new description
+	video.Snippet.Description = description
+	if _, err := s.service.Videos.Update([]string{"snippet"}, &youtube.Video{Id: video.Id, Snippet: video.Snippet}).Do(); err != nil {
+		return fmt.Errorf("error updating video: %w", err)
+	}
+	return nil
+}
+
+// VideoDetails are a broadcast video's metadata that LiveBroadcasts can't set
+type VideoDetails struct {
+	CategoryID    string
+	Tags          []string
+	Language      string
+	AudioLanguage string
+}
+
+// SetVideoDetails sets a video's category, tags and languages. Empty fields keep the
+// video's current values.
+func (s *StreamScheduler) SetVideoDetails(videoID string, details VideoDetails) error {
+	resp, err := s.service.Videos.List([]string{"snippet"}).Id(videoID).Do()
+	if err != nil {
+		return fmt.Errorf("error fetching video: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return fmt.Errorf("video not found: %s", videoID)
+	}
+
+	// Updating the snippet replaces it entirely, so change the current one
+	snippet := resp.Items[0].Snippet
+	if details.CategoryID != "" {
+		snippet.CategoryId = details.CategoryID
+	}
+	if len(details.Tags) > 0 {
+		snippet.Tags = details.Tags
+	}
+	if details.Language != "" {
+		snippet.DefaultLanguage = details.Language
+	}
+	if details.AudioLanguage != "" {
+		snippet.DefaultAudioLanguage = details.AudioLanguage
+	}
+	if _, err := s.service.Videos.Update([]string{"snippet"}, &youtube.Video{Id: videoID, Snippet: snippet}).Do(); err != nil {
+		return fmt.Errorf("error updating video: %w", err)
+	}
+	return nil
+}
+
+// AddToPlaylist adds a video to the channel's playlist with the given title, creating the
+// playlist with the given privacy if there isn't one. A video already in it isn't added again.
+func (s *StreamScheduler) AddToPlaylist(videoID, title, privacy string) error {
+	playlist, err := s.findPlaylist(title)
+	if err != nil {
+		return err
+	}
+	if playlist == nil {
+		playlist, err = s.service.Playlists.Insert([]string{"snippet", "status"}, &youtube.Playlist{
+			Snippet: &youtube.PlaylistSnippet{Title: title},
+			Status:  &youtube.PlaylistStatus{PrivacyStatus: privacy},
+		}).Do()
+		if err != nil {
+			return fmt.Errorf("error creating playlist: %w", err)
+		}
+		fmt.Printf("Playlist created: %s (%s)\n", title, playlist.Id)
+	}
+
+	items, err := s.service.PlaylistItems.List([]string{"id"}).PlaylistId(playlist.Id).VideoId(videoID).Do()
+	if err != nil {
+		return fmt.Errorf("error listing playlist items: %w", err)
+	}
+	if len(items.Items) > 0 {
+		return nil
+	}
+
+	_, err = s.service.PlaylistItems.Insert([]string{"snippet"}, &youtube.PlaylistItem{
+		Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId: playlist.Id,
+			ResourceId: &youtube.ResourceId{Kind: "youtube#video", VideoId: videoID},
+		},
+	}).Do()
+	if err != nil {
+		return fmt.Errorf("error adding video to playlist: %w", err)
+	}
+	fmt.Printf("Added to playlist: %s\n", title)
+	return nil
+}
+
+// findPlaylist returns the channel's playlist with the given title, or nil
+func (s *StreamScheduler) findPlaylist(title string) (*youtube.Playlist, error) {
+	pageToken := ""
+	for {
+		resp, err := s.service.Playlists.List([]string{"snippet"}).Mine(true).MaxResults(50).PageToken(pageToken).Do()
+		if err != nil {
+			return nil, fmt.Errorf("error listing playlists: %w", err)
+		}
+		for _, p := range resp.Items {
+			if p.Snippet != nil && p.Snippet.Title == title {
+				return p, nil
+			}
+		}
+		if resp.NextPageToken == "" {
+			return nil, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// SetThumbnail uploads a video's custom thumbnail, a JPEG or PNG of at most 2MB. The
+// channel must be verified to use custom thumbnails.
+func (s *StreamScheduler) SetThumbnail(videoID string, image io.Reader) error {
+	if _, err := s.service.Thumbnails.Set(videoID).Media(image).Do(); err != nil {
+		return fmt.Errorf("error uploading thumbnail: %w", err)
+	}
+	return nil
+}
+
 func (s *StreamScheduler) EndStream(broadcastID string) error {
 	fmt.Println("Ending broadcast...")
 
 	completeCall := s.service.LiveBroadcasts.Transition("complete", broadcastID, []string{"status"})
 	_, err := completeCall.Do()
 	if err != nil {
-		return fmt.Errorf("error ending broadcast: %v", err)
+		return fmt.Errorf("error ending broadcast: %w", err)
 	}
+	s.transitioned(broadcastID, "complete")
 
 	fmt.Println("Broadcast ended successfully")
 	fmt.Printf("  Video available at: https://youtube.com/watch?v=%s\n", broadcastID)
@@ -243,7 +681,7 @@ func (s *StreamScheduler) WaitAndGoLive(scheduledTime time.Time, broadcastID str
 
 	if duration <= 0 {
 		fmt.Println("Scheduled time is in the past. Going live immediately...")
-		if err := s.GoLive(broadcastID); err != nil {
+		if err := s.GoLive(broadcastID, DefaultGoLiveOptions()); err != nil {
 			log.Fatalf("Error going live: %v", err)
 		}
 		return
@@ -265,7 +703,7 @@ func (s *StreamScheduler) WaitAndGoLive(scheduledTime time.Time, broadcastID str
 		select {
 		case <-done:
 			fmt.Println("\nScheduled time reached!")
-			if err := s.GoLive(broadcastID); err != nil {
+			if err := s.GoLive(broadcastID, DefaultGoLiveOptions()); err != nil {
 				log.Fatalf("Error going live: %v", err)
 			}
 			return
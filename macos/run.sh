@@ -1,3 +1,3 @@
 #!/bin/bash
 
-../launcher/launcher stream schedule --city "San Bernardino, CA" --time 2026-01-28T11:15:00
+../launcher/launcher stream schedule --time 2026-01-28T11:15:00
@@ -1,3 +1,3 @@
 #!/bin/bash
 
-../launcher/launcher stream schedule --city "San Bernardino, CA" --time SUNRISE
+../launcher/launcher stream schedule --time SUNRISE